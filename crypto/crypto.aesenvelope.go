@@ -0,0 +1,98 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+const (
+	// aesEnvelopeVersion is the version byte written at the start of each envelope.
+	aesEnvelopeVersion = 1
+
+	// aesEnvelopeMaxKeyIDLength is the longest key identifier an envelope can carry.
+	aesEnvelopeMaxKeyIDLength = 255
+)
+
+// EncryptAESEnvelope encrypts a message with AES using GCM, wrapping it in an envelope that records the key identifier.
+// The envelope header and associatedData are authenticated but not encrypted; the same associatedData must be passed to DecryptAESEnvelope.
+func EncryptAESEnvelope(key string, keyID string, inputData []byte, associatedData []byte) ([]byte, error) {
+	if len(keyID) > aesEnvelopeMaxKeyIDLength {
+		return nil, errors.New("key identifier too long")
+	}
+
+	aesGCM, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build header.
+	header := make([]byte, 0, 2+len(keyID)+aesGCM.NonceSize())
+	header = append(header, aesEnvelopeVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	outputData := append(header, nonce...)
+	return aesGCM.Seal(outputData, nonce, inputData, aesEnvelopeAdditionalData(header, associatedData)), nil
+}
+
+// DecryptAESEnvelope decrypts a message produced by EncryptAESEnvelope, verifying associatedData and returning the key identifier.
+// Messages in the raw format produced by EncryptAES are also accepted when associatedData is empty; their key identifier is blank.
+func DecryptAESEnvelope(key string, inputData []byte, associatedData []byte) (outputData []byte, keyID string, err error) {
+	aesGCM, err := newAESGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Attempt to decrypt as an envelope.
+	header, keyID, err := parseAESEnvelopeHeader(inputData)
+	if err == nil && len(inputData) >= len(header)+aesGCM.NonceSize() {
+		nonce := inputData[len(header) : len(header)+aesGCM.NonceSize()]
+		outputData, err = aesGCM.Open(nil, nonce, inputData[len(header)+aesGCM.NonceSize():], aesEnvelopeAdditionalData(header, associatedData))
+		if err == nil {
+			return outputData, keyID, nil
+		}
+	}
+
+	// Fall back to the raw format, which cannot carry associated data.
+	if len(associatedData) > 0 {
+		return nil, "", errors.New("message authentication failed")
+	}
+	outputData, err = DecryptAES(key, inputData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return outputData, "", nil
+}
+
+// AESEnvelopeKeyID returns the key identifier recorded in an envelope without decrypting it.
+func AESEnvelopeKeyID(inputData []byte) (string, error) {
+	_, keyID, err := parseAESEnvelopeHeader(inputData)
+	return keyID, err
+}
+
+// parseAESEnvelopeHeader returns the header bytes and key identifier of an envelope.
+func parseAESEnvelopeHeader(inputData []byte) ([]byte, string, error) {
+	if len(inputData) < 2 || inputData[0] != aesEnvelopeVersion {
+		return nil, "", errors.New("unrecognized envelope version")
+	}
+
+	headerLength := 2 + int(inputData[1])
+	if len(inputData) < headerLength {
+		return nil, "", errors.New("envelope header truncated")
+	}
+
+	return inputData[:headerLength], string(inputData[2:headerLength]), nil
+}
+
+// aesEnvelopeAdditionalData returns the additional data authenticated by GCM: the envelope header followed by the caller's associated data.
+func aesEnvelopeAdditionalData(header []byte, associatedData []byte) []byte {
+	additionalData := make([]byte, 0, len(header)+len(associatedData))
+	additionalData = append(additionalData, header...)
+	return append(additionalData, associatedData...)
+}
@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEncryptAESEnvelope tests EncryptAESEnvelope() and DecryptAESEnvelope().
+func TestEncryptAESEnvelope(t *testing.T) {
+	key, err := GenerateAESKey(32)
+	assert.NoError(t, err)
+	plaintext := []byte("sample message")
+
+	// Test a round trip with associated data.
+	encrypted, err := EncryptAESEnvelope(key, "key-1", plaintext, []byte("record-123"))
+	assert.NoError(t, err)
+	decrypted, keyID, err := DecryptAESEnvelope(key, encrypted, []byte("record-123"))
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.Equal(t, "key-1", keyID)
+
+	// Test reading the key identifier without decrypting.
+	keyID, err = AESEnvelopeKeyID(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", keyID)
+
+	// Test mismatched associated data.
+	_, _, err = DecryptAESEnvelope(key, encrypted, []byte("record-456"))
+	assert.Error(t, err, "Mismatched associated data.")
+	_, _, err = DecryptAESEnvelope(key, encrypted, nil)
+	assert.Error(t, err, "Missing associated data.")
+
+	// Test a tampered key identifier.
+	tampered := append([]byte{}, encrypted...)
+	tampered[2] = 'K'
+	_, _, err = DecryptAESEnvelope(key, tampered, []byte("record-123"))
+	assert.Error(t, err, "Tampered key identifier.")
+
+	// Test the raw format.
+	encrypted, err = EncryptAES(key, plaintext)
+	assert.NoError(t, err)
+	decrypted, keyID, err = DecryptAESEnvelope(key, encrypted, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.Equal(t, "", keyID)
+	_, _, err = DecryptAESEnvelope(key, encrypted, []byte("record-123"))
+	assert.Error(t, err, "Raw format with associated data.")
+
+	// Test an oversized key identifier.
+	_, err = EncryptAESEnvelope(key, strings.Repeat("k", 256), plaintext, nil)
+	assert.Error(t, err, "Oversized key identifier.")
+}
@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// aesStreamVersion is the version byte written at the start of each stream.
+	aesStreamVersion = 1
+
+	// aesStreamHeaderSize is the size of the stream header (version, segment size and nonce prefix).
+	aesStreamHeaderSize = 1 + 4 + aesStreamNoncePrefixSize
+
+	// aesStreamNoncePrefixSize is the size of the random nonce prefix shared by all segments.
+	aesStreamNoncePrefixSize = 7
+
+	// aesStreamMaxSegmentSize is the largest segment size accepted when decrypting.
+	aesStreamMaxSegmentSize = 16 * 1024 * 1024
+
+	// AESStreamSegmentSize is the plaintext size of each authenticated segment.
+	AESStreamSegmentSize = 64 * 1024
+)
+
+// EncryptAESStream encrypts src to dst with AES using GCM, splitting the input into authenticated segments.
+// Each segment has its own nonce derived from a random prefix, its position and whether it is the final segment, so truncated or reordered streams fail to decrypt.
+func EncryptAESStream(key string, dst io.Writer, src io.Reader) error {
+	aesGCM, err := newAESGCM(key)
+	if err != nil {
+		return err
+	}
+
+	// Write header.
+	header := make([]byte, aesStreamHeaderSize)
+	header[0] = aesStreamVersion
+	binary.BigEndian.PutUint32(header[1:5], AESStreamSegmentSize)
+	if _, err = io.ReadFull(rand.Reader, header[5:]); err != nil {
+		return err
+	}
+	if _, err = dst.Write(header); err != nil {
+		return err
+	}
+
+	// Encrypt each segment.
+	reader := bufio.NewReader(src)
+	plaintext := make([]byte, AESStreamSegmentSize)
+	ciphertext := make([]byte, 0, AESStreamSegmentSize+aesGCM.Overhead())
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(reader, plaintext)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		last, err := isFinalSegment(reader, n < len(plaintext))
+		if err != nil {
+			return err
+		}
+
+		ciphertext = aesGCM.Seal(ciphertext[:0], aesStreamNonce(header, counter, last), plaintext[:n], header)
+		if _, err = dst.Write(ciphertext); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		if counter == math.MaxUint32 {
+			return errors.New("stream exceeds maximum number of segments")
+		}
+	}
+}
+
+// DecryptAESStream decrypts a stream produced by EncryptAESStream from src to dst.
+// Each segment is authenticated before it is written, but an error may still be returned after earlier segments have been written, in which case the output should be discarded.
+func DecryptAESStream(key string, dst io.Writer, src io.Reader) error {
+	aesGCM, err := newAESGCM(key)
+	if err != nil {
+		return err
+	}
+
+	// Read header.
+	header := make([]byte, aesStreamHeaderSize)
+	if _, err = io.ReadFull(src, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return errors.New("stream header truncated")
+		}
+		return err
+	}
+	if header[0] != aesStreamVersion {
+		return errors.New("unsupported stream version")
+	}
+	segmentSize := binary.BigEndian.Uint32(header[1:5])
+	if segmentSize == 0 || segmentSize > aesStreamMaxSegmentSize {
+		return errors.New("invalid stream segment size")
+	}
+
+	// Decrypt each segment.
+	reader := bufio.NewReader(src)
+	ciphertext := make([]byte, int(segmentSize)+aesGCM.Overhead())
+	plaintext := make([]byte, 0, segmentSize)
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(reader, ciphertext)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if n < aesGCM.Overhead() {
+			return errors.New("stream truncated")
+		}
+		last, err := isFinalSegment(reader, n < len(ciphertext))
+		if err != nil {
+			return err
+		}
+
+		plaintext, err = aesGCM.Open(plaintext[:0], aesStreamNonce(header, counter, last), ciphertext[:n], header)
+		if err != nil {
+			return errors.New("stream segment failed authentication")
+		}
+		if _, err = dst.Write(plaintext); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		if counter == math.MaxUint32 {
+			return errors.New("stream exceeds maximum number of segments")
+		}
+	}
+}
+
+// aesStreamNonce returns the nonce for a segment: the header's nonce prefix, the segment counter and a final segment flag.
+func aesStreamNonce(header []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, header[5:])
+	binary.BigEndian.PutUint32(nonce[aesStreamNoncePrefixSize:], counter)
+	if last {
+		nonce[11] = 1
+	}
+
+	return nonce
+}
+
+// isFinalSegment reports whether the segment just read is the last one in the stream.
+func isFinalSegment(reader *bufio.Reader, short bool) (bool, error) {
+	if short {
+		return true, nil
+	}
+	if _, err := reader.Peek(1); err != nil {
+		if err == io.EOF {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return false, nil
+}
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEncryptAESStream tests EncryptAESStream() and DecryptAESStream().
+func TestEncryptAESStream(t *testing.T) {
+	key, err := GenerateAESKey(32)
+	assert.NoError(t, err)
+
+	// Round-trip various sizes, including exact segment boundaries.
+	for _, size := range []int{0, 1, AESStreamSegmentSize - 1, AESStreamSegmentSize, 3*AESStreamSegmentSize + 5} {
+		plaintext := make([]byte, size)
+		_, err = rand.Read(plaintext)
+		assert.NoError(t, err)
+
+		encrypted := new(bytes.Buffer)
+		err = EncryptAESStream(key, encrypted, bytes.NewReader(plaintext))
+		assert.NoError(t, err)
+
+		decrypted := new(bytes.Buffer)
+		err = DecryptAESStream(key, decrypted, bytes.NewReader(encrypted.Bytes()))
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(plaintext, decrypted.Bytes()), "Round trip.")
+	}
+
+	// Encrypt a multi-segment stream for tampering checks.
+	plaintext := make([]byte, 3*AESStreamSegmentSize)
+	_, err = rand.Read(plaintext)
+	assert.NoError(t, err)
+	encrypted := new(bytes.Buffer)
+	err = EncryptAESStream(key, encrypted, bytes.NewReader(plaintext))
+	assert.NoError(t, err)
+	ciphertext := encrypted.Bytes()
+	segmentLength := AESStreamSegmentSize + 16
+
+	// Test truncation at a segment boundary.
+	truncated := ciphertext[:aesStreamHeaderSize+segmentLength]
+	err = DecryptAESStream(key, new(bytes.Buffer), bytes.NewReader(truncated))
+	assert.Error(t, err, "Truncated stream.")
+
+	// Test truncation within a segment.
+	err = DecryptAESStream(key, new(bytes.Buffer), bytes.NewReader(ciphertext[:len(ciphertext)-1]))
+	assert.Error(t, err, "Truncated segment.")
+
+	// Test truncation of the header.
+	err = DecryptAESStream(key, new(bytes.Buffer), bytes.NewReader(ciphertext[:4]))
+	assert.Error(t, err, "Truncated header.")
+
+	// Test reordered segments.
+	reordered := append([]byte{}, ciphertext[:aesStreamHeaderSize]...)
+	reordered = append(reordered, ciphertext[aesStreamHeaderSize+segmentLength:aesStreamHeaderSize+2*segmentLength]...)
+	reordered = append(reordered, ciphertext[aesStreamHeaderSize:aesStreamHeaderSize+segmentLength]...)
+	reordered = append(reordered, ciphertext[aesStreamHeaderSize+2*segmentLength:]...)
+	err = DecryptAESStream(key, new(bytes.Buffer), bytes.NewReader(reordered))
+	assert.Error(t, err, "Reordered segments.")
+
+	// Test the wrong key.
+	otherKey, err := GenerateAESKey(32)
+	assert.NoError(t, err)
+	err = DecryptAESStream(otherKey, new(bytes.Buffer), bytes.NewReader(ciphertext))
+	assert.Error(t, err, "Wrong key.")
+
+	// Test an invalid key.
+	err = EncryptAESStream("not hex", new(bytes.Buffer), bytes.NewReader(plaintext))
+	assert.Error(t, err, "Invalid key.")
+}
@@ -0,0 +1,410 @@
+package crypto
+
+import (
+	"bytes"
+	baseCrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCAValidity is the validity of certificate authorities when none is specified.
+	defaultCAValidity = 10 * 365 * 24 * time.Hour
+
+	// defaultLeafValidity is the validity of leaf certificates when none is specified.
+	defaultLeafValidity = 365 * 24 * time.Hour
+)
+
+// CA defines a certificate authority able to issue certificates.
+type CA struct {
+	CRLDistributionPoints []string            // CRL URLs embedded in issued certificates.
+	Certificate           *x509.Certificate   // CA certificate.
+	Chain                 []*x509.Certificate // Certificates above the CA certificate, ending with the root; empty for a root.
+	OCSPServers           []string            // OCSP responder URLs embedded in issued certificates.
+	PrivateKey            baseCrypto.Signer   // CA private key.
+
+	crlNumber int64
+	issued    map[string]bool
+	lock      sync.Mutex
+	revoked   map[string]revocation
+}
+
+// CertificateOptions defines the subject, names, key and validity of a certificate.
+type CertificateOptions struct {
+	DNSNames       []string           // DNS subject alternative names.
+	ECDSACurve     string             // Key type, as accepted by GenerateX509Certificate; defaults to P-256 when RSABits is also unset.
+	EmailAddresses []string           // Email subject alternative names.
+	ExtKeyUsage    []x509.ExtKeyUsage // Extended key usages; defaults to server authentication for leaf certificates.
+	IPAddresses    []net.IP           // IP subject alternative names.
+	KeyPassphrase  string             // Passphrase used to encrypt issued private keys; unencrypted if empty.
+	KeyUsage       x509.KeyUsage      // Key usages; defaults to digital signature for leaf certificates, plus key encipherment for RSA keys.
+	MaxPathLen     int                // Maximum number of intermediates below a CA certificate; zero means unlimited unless MaxPathLenZero is set.
+	MaxPathLenZero bool               // Restricts a CA certificate to issuing leaf certificates only, when MaxPathLen is zero.
+	RSABits        int                // RSA key size, used when ECDSACurve is "" or "rsa".
+	Subject        pkix.Name          // Certificate subject.
+	URIs           []*url.URL         // URI subject alternative names.
+	ValidFor       time.Duration      // Validity period; defaults to ten years for CAs and one year for leaf certificates.
+	ValidFrom      time.Time          // Start of validity; defaults to now.
+}
+
+// NewCA creates a self-signed root certificate authority.
+func NewCA(options CertificateOptions) (*CA, error) {
+	privateKey, err := generateCertificateKey(options)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := caTemplate(options)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := createCertificate(template, template, publicKey(privateKey), privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{
+		Certificate: certificate,
+		PrivateKey:  privateKey,
+	}, nil
+}
+
+// LoadCA loads a certificate authority from PEM-encoded data.
+// The certificate data must start with the CA certificate and may be followed by the certificates above it.
+func LoadCA(crtBytes []byte, keyBytes []byte) (*CA, error) {
+	certificates, err := parseCertificatesPEM(crtBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	if !certificates[0].IsCA {
+		return nil, errors.New("certificate is not a certificate authority")
+	}
+
+	privateKey, err := ParsePrivateKey(keyBytes, "")
+	if err != nil {
+		return nil, err
+	}
+	if !publicKeysEqual(certificates[0].PublicKey, privateKey.Public()) {
+		return nil, errors.New("private key does not match certificate")
+	}
+
+	return &CA{
+		Certificate: certificates[0],
+		Chain:       certificates[1:],
+		PrivateKey:  privateKey,
+	}, nil
+}
+
+// LoadOrCreateCA loads a certificate authority from files, creating a root and saving it if the files do not exist.
+func LoadOrCreateCA(crtPath string, keyPath string, options CertificateOptions) (*CA, error) {
+	crtBytes, crtErr := os.ReadFile(crtPath)
+	keyBytes, keyErr := os.ReadFile(keyPath)
+	if crtErr == nil && keyErr == nil {
+		return LoadCA(crtBytes, keyBytes)
+	}
+	if !os.IsNotExist(crtErr) && crtErr != nil {
+		return nil, crtErr
+	}
+	if !os.IsNotExist(keyErr) && keyErr != nil {
+		return nil, keyErr
+	}
+	if crtErr == nil || keyErr == nil {
+		return nil, errors.New("only one of certificate and key files exists")
+	}
+
+	ca, err := NewCA(options)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err = ca.PrivateKeyPEM()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(keyPath, keyBytes, 0600); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(crtPath, ca.CertificatePEM(), 0644); err != nil {
+		return nil, err
+	}
+
+	return ca, nil
+}
+
+// CertificatePEM returns the PEM-encoded CA certificate followed by the certificates above it.
+func (ca *CA) CertificatePEM() []byte {
+	return encodeCertificatesPEM(append([]*x509.Certificate{ca.Certificate}, ca.Chain...))
+}
+
+// CertPool returns a pool containing the root certificate, for verifying issued certificates.
+func (ca *CA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Root())
+
+	return pool
+}
+
+// Issue issues a leaf certificate with a newly generated key.
+// The returned certificate data includes any intermediates needed to chain to the root.
+func (ca *CA) Issue(options CertificateOptions) (crtBytes []byte, keyBytes []byte, err error) {
+	privateKey, err := generateCertificateKey(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template, err := leafTemplate(options, publicKey(privateKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	certificate, err := ca.sign(template, publicKey(privateKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes, err = MarshalPrivateKeyPEM(privateKey, options.KeyPassphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ca.bundlePEM(certificate), keyBytes, nil
+}
+
+// IssueClientCertificate issues a leaf certificate for TLS client authentication.
+func (ca *CA) IssueClientCertificate(options CertificateOptions) (crtBytes []byte, keyBytes []byte, err error) {
+	options.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	return ca.Issue(options)
+}
+
+// IssueServerCertificate issues a leaf certificate for TLS server authentication.
+func (ca *CA) IssueServerCertificate(options CertificateOptions) (crtBytes []byte, keyBytes []byte, err error) {
+	options.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	return ca.Issue(options)
+}
+
+// MarkIssued records that the CA issued a certificate, so that its OCSP responder reports it as good.
+// Certificates issued by the CA are recorded automatically; use MarkIssued to restore the record after loading a CA.
+func (ca *CA) MarkIssued(serialNumber *big.Int) {
+	ca.lock.Lock()
+	defer ca.lock.Unlock()
+
+	if ca.issued == nil {
+		ca.issued = make(map[string]bool)
+	}
+	ca.issued[serialNumber.String()] = true
+}
+
+// NewIntermediate creates an intermediate certificate authority signed by this one.
+func (ca *CA) NewIntermediate(options CertificateOptions) (*CA, error) {
+	privateKey, err := generateCertificateKey(options)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := caTemplate(options)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := ca.sign(template, publicKey(privateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{
+		Certificate: certificate,
+		Chain:       append([]*x509.Certificate{ca.Certificate}, ca.Chain...),
+		PrivateKey:  privateKey,
+	}, nil
+}
+
+// PrivateKeyPEM returns the PEM-encoded CA private key.
+func (ca *CA) PrivateKeyPEM() ([]byte, error) {
+	return MarshalPrivateKeyPEM(ca.PrivateKey, "")
+}
+
+// Root returns the root certificate of the chain.
+func (ca *CA) Root() *x509.Certificate {
+	if len(ca.Chain) == 0 {
+		return ca.Certificate
+	}
+
+	return ca.Chain[len(ca.Chain)-1]
+}
+
+// bundlePEM returns a PEM-encoded certificate followed by the intermediates needed to chain it to the root.
+func (ca *CA) bundlePEM(certificate *x509.Certificate) []byte {
+	certificates := []*x509.Certificate{certificate}
+	for _, issuer := range append([]*x509.Certificate{ca.Certificate}, ca.Chain...) {
+		if !bytes.Equal(issuer.RawIssuer, issuer.RawSubject) {
+			certificates = append(certificates, issuer)
+		}
+	}
+
+	return encodeCertificatesPEM(certificates)
+}
+
+// sign signs a certificate template with the CA key.
+func (ca *CA) sign(template *x509.Certificate, pub interface{}) (*x509.Certificate, error) {
+	if template.NotAfter.After(ca.Certificate.NotAfter) {
+		template.NotAfter = ca.Certificate.NotAfter
+	}
+	template.CRLDistributionPoints = ca.CRLDistributionPoints
+	template.OCSPServer = ca.OCSPServers
+
+	certificate, err := createCertificate(template, ca.Certificate, pub, ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	ca.MarkIssued(certificate.SerialNumber)
+
+	return certificate, nil
+}
+
+// caTemplate returns a certificate template for a certificate authority.
+func caTemplate(options CertificateOptions) (*x509.Certificate, error) {
+	if options.ValidFor == 0 {
+		options.ValidFor = defaultCAValidity
+	}
+	template, err := certificateTemplate(options)
+	if err != nil {
+		return nil, err
+	}
+
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	template.MaxPathLen = options.MaxPathLen
+	template.MaxPathLenZero = options.MaxPathLenZero
+
+	return template, nil
+}
+
+// certificateTemplate returns a certificate template populated from options.
+func certificateTemplate(options CertificateOptions) (*x509.Certificate, error) {
+	if options.ValidFor < 0 {
+		return nil, errors.New("validFor must not be negative")
+	}
+	if options.ValidFrom.IsZero() {
+		options.ValidFrom = time.Now().Add(-time.Minute)
+	}
+
+	serialNumber, err := generateSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	return &x509.Certificate{
+		BasicConstraintsValid: true,
+		DNSNames:              options.DNSNames,
+		EmailAddresses:        options.EmailAddresses,
+		ExtKeyUsage:           options.ExtKeyUsage,
+		IPAddresses:           options.IPAddresses,
+		NotAfter:              options.ValidFrom.Add(options.ValidFor),
+		NotBefore:             options.ValidFrom,
+		SerialNumber:          serialNumber,
+		Subject:               options.Subject,
+		URIs:                  options.URIs,
+	}, nil
+}
+
+// createCertificate signs a certificate template and parses the result.
+func createCertificate(template *x509.Certificate, parent *x509.Certificate, pub interface{}, priv interface{}) (*x509.Certificate, error) {
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(derBytes)
+}
+
+// encodeCertificatesPEM returns PEM-encoded certificates.
+func encodeCertificatesPEM(certificates []*x509.Certificate) []byte {
+	buffer := new(bytes.Buffer)
+	for _, certificate := range certificates {
+		buffer.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})) // nolint
+	}
+
+	return buffer.Bytes()
+}
+
+// generateCertificateKey generates the private key described by options.
+func generateCertificateKey(options CertificateOptions) (baseCrypto.Signer, error) {
+	if options.ECDSACurve == "" && options.RSABits == 0 {
+		options.ECDSACurve = "p256"
+	}
+
+	privateKey, err := generatePrivateKey(options.RSABits, options.ECDSACurve)
+	if err != nil {
+		return nil, err
+	}
+
+	return privateKey.(baseCrypto.Signer), nil
+}
+
+// leafTemplate returns a certificate template for a leaf certificate with the specified public key.
+func leafTemplate(options CertificateOptions, pub interface{}) (*x509.Certificate, error) {
+	if options.ValidFor == 0 {
+		options.ValidFor = defaultLeafValidity
+	}
+	if len(options.ExtKeyUsage) == 0 {
+		options.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+	if len(options.DNSNames) == 0 && len(options.IPAddresses) == 0 && len(options.URIs) == 0 && len(options.EmailAddresses) == 0 && options.Subject.CommonName == "" {
+		return nil, errors.New("certificate has no subject or subject alternative names")
+	}
+
+	template, err := certificateTemplate(options)
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = options.KeyUsage
+	if template.KeyUsage == 0 {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			template.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+	}
+
+	return template, nil
+}
+
+// parseCertificatesPEM parses all PEM-encoded certificates in data.
+func parseCertificatesPEM(data []byte) ([]*x509.Certificate, error) {
+	certificates := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+// publicKeysEqual reports whether two public keys are equal.
+func publicKeysEqual(a interface{}, b interface{}) bool {
+	key, ok := a.(interface {
+		Equal(baseCrypto.PublicKey) bool
+	})
+	return ok && key.Equal(b)
+}
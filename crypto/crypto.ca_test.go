@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCA tests CA.
+func TestCA(t *testing.T) {
+	ca, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Test Root", Organization: []string{"Test"}}})
+	assert.NoError(t, err)
+	assert.True(t, ca.Certificate.IsCA)
+	assert.Equal(t, ca.Certificate, ca.Root())
+
+	// Test issuing a server certificate with multiple SANs.
+	spiffeURI, err := url.Parse("spiffe://test/service")
+	assert.NoError(t, err)
+	crtBytes, keyBytes, err := ca.IssueServerCertificate(CertificateOptions{
+		DNSNames:       []string{"localhost", "service.test"},
+		EmailAddresses: []string{"ops@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		Subject:        pkix.Name{CommonName: "service", OrganizationalUnit: []string{"Platform"}},
+		URIs:           []*url.URL{spiffeURI},
+	})
+	assert.NoError(t, err)
+	keyPair, err := tls.X509KeyPair(crtBytes, keyBytes)
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost", "service.test"}, leaf.DNSNames)
+	assert.Len(t, leaf.IPAddresses, 2)
+	assert.Equal(t, "spiffe://test/service", leaf.URIs[0].String())
+	assert.Equal(t, []string{"ops@example.com"}, leaf.EmailAddresses)
+	assert.Equal(t, []string{"Platform"}, leaf.Subject.OrganizationalUnit)
+	assert.Equal(t, x509.KeyUsageDigitalSignature, leaf.KeyUsage, "No key encipherment for ECDSA keys.")
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "service.test", Roots: ca.CertPool()})
+	assert.NoError(t, err)
+
+	// Test issuing a client certificate.
+	crtBytes, _, err = ca.IssueClientCertificate(CertificateOptions{Subject: pkix.Name{CommonName: "client"}, RSABits: 2048})
+	assert.NoError(t, err)
+	certificates, err := parseCertificatesPEM(crtBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment, certificates[0].KeyUsage, "Key encipherment for RSA keys.")
+	_, err = certificates[0].Verify(x509.VerifyOptions{Roots: ca.CertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+	_, err = certificates[0].Verify(x509.VerifyOptions{Roots: ca.CertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	assert.Error(t, err, "Client certificate used for server authentication.")
+
+	// Test issuing through an intermediate.
+	intermediate, err := ca.NewIntermediate(CertificateOptions{Subject: pkix.Name{CommonName: "Test Intermediate"}, MaxPathLen: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, ca.Certificate, intermediate.Root())
+	crtBytes, _, err = intermediate.IssueServerCertificate(CertificateOptions{DNSNames: []string{"leaf.test"}})
+	assert.NoError(t, err)
+	certificates, err = parseCertificatesPEM(crtBytes)
+	assert.NoError(t, err)
+	assert.Len(t, certificates, 2, "Leaf bundled with intermediate.")
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(certificates[1])
+	_, err = certificates[0].Verify(x509.VerifyOptions{DNSName: "leaf.test", Intermediates: intermediates, Roots: intermediate.CertPool()})
+	assert.NoError(t, err)
+
+	// Test an intermediate restricted to issuing leaf certificates.
+	leafOnly, err := ca.NewIntermediate(CertificateOptions{Subject: pkix.Name{CommonName: "Leaf-Only Intermediate"}, MaxPathLenZero: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, leafOnly.Certificate.MaxPathLen)
+	assert.True(t, leafOnly.Certificate.MaxPathLenZero)
+	crtBytes, _, err = leafOnly.IssueServerCertificate(CertificateOptions{DNSNames: []string{"leaf.test"}})
+	assert.NoError(t, err)
+	certificates, err = parseCertificatesPEM(crtBytes)
+	assert.NoError(t, err)
+	intermediates = x509.NewCertPool()
+	intermediates.AddCert(certificates[1])
+	_, err = certificates[0].Verify(x509.VerifyOptions{DNSName: "leaf.test", Intermediates: intermediates, Roots: ca.CertPool()})
+	assert.NoError(t, err)
+	nested, err := leafOnly.NewIntermediate(CertificateOptions{Subject: pkix.Name{CommonName: "Nested Intermediate"}})
+	assert.NoError(t, err)
+	crtBytes, _, err = nested.IssueServerCertificate(CertificateOptions{DNSNames: []string{"nested.test"}})
+	assert.NoError(t, err)
+	certificates, err = parseCertificatesPEM(crtBytes)
+	assert.NoError(t, err)
+	intermediates = x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	_, err = certificates[0].Verify(x509.VerifyOptions{DNSName: "nested.test", Intermediates: intermediates, Roots: ca.CertPool()})
+	assert.Error(t, err, "Path length exceeded.")
+
+	// Test an Ed25519 leaf with an encrypted key.
+	crtBytes, keyBytes, err = ca.Issue(CertificateOptions{DNSNames: []string{"ed25519.test"}, ECDSACurve: "ed25519", KeyPassphrase: "secret"})
+	assert.NoError(t, err)
+	signer, err := ParsePrivateKey(keyBytes, "secret")
+	assert.NoError(t, err)
+	certificates, err = parseCertificatesPEM(crtBytes)
+	assert.NoError(t, err)
+	assert.True(t, publicKeysEqual(certificates[0].PublicKey, signer.Public()))
+
+	// Test a leaf without names.
+	_, _, err = ca.Issue(CertificateOptions{})
+	assert.Error(t, err, "No names.")
+
+	// Test loading and creating from files.
+	directory := t.TempDir()
+	crtPath := filepath.Join(directory, "ca.crt")
+	keyPath := filepath.Join(directory, "ca.key")
+	created, err := LoadOrCreateCA(crtPath, keyPath, CertificateOptions{Subject: pkix.Name{CommonName: "File Root"}})
+	assert.NoError(t, err)
+	loaded, err := LoadOrCreateCA(crtPath, keyPath, CertificateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, created.Certificate.Raw, loaded.Certificate.Raw)
+	assert.Equal(t, "File Root", loaded.Certificate.Subject.CommonName)
+
+	// Test loading an intermediate with its chain.
+	keyBytes, err = intermediate.PrivateKeyPEM()
+	assert.NoError(t, err)
+	loaded, err = LoadCA(intermediate.CertificatePEM(), keyBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, ca.Certificate.Raw, loaded.Root().Raw)
+
+	// Test loading a mismatched key.
+	_, err = LoadCA(ca.CertificatePEM(), keyBytes)
+	assert.Error(t, err, "Mismatched key.")
+}
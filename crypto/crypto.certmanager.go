@@ -0,0 +1,279 @@
+package crypto
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+const (
+	// defaultCheckInterval is how often the certificate manager checks its certificate when none is specified.
+	defaultCheckInterval = time.Minute
+
+	// defaultRenewFraction is the fraction of a certificate's lifetime after which it is renewed when none is specified.
+	defaultRenewFraction = 2.0 / 3.0
+
+	// minimumRetryDelay is the first wait before retrying a renewal that failed or left the certificate due for renewal.
+	minimumRetryDelay = time.Second
+)
+
+// CertificateSource issues a PEM-encoded certificate and private key.
+type CertificateSource func() (crtBytes []byte, keyBytes []byte, err error)
+
+// CertificateExpiry describes the lifetime of a managed certificate.
+type CertificateExpiry struct {
+	NotAfter  time.Time     // End of validity.
+	NotBefore time.Time     // Start of validity.
+	Remaining time.Duration // Time until the certificate expires.
+	RenewAt   time.Time     // Time at which the certificate will be renewed.
+	Renewed   bool          // Whether the certificate was just issued or loaded.
+}
+
+// CertificateManager supplies TLS certificates from a source, caching them on disk and renewing them before they expire.
+// Renewed certificates are used for new handshakes without restarting listeners.
+type CertificateManager struct {
+	CacheDir      string                  // Directory in which certificates are cached; caching is disabled if empty.
+	CheckInterval time.Duration           // How often to check the certificate; defaults to one minute.
+	Clock         timeUtils.Clock         // Clock used for renewal times and waits; defaults to time.RealClock.
+	Name          string                  // Cache file name prefix; defaults to "certificate".
+	OnExpiry      func(CertificateExpiry) // Called with expiry metrics after each check.
+	RenewFraction float64                 // Fraction of the lifetime after which to renew; defaults to two thirds.
+	Source        CertificateSource       // Issues new certificates.
+
+	current atomic.Pointer[managedCertificate]
+	lock    sync.Mutex
+}
+
+// managedCertificate defines a parsed certificate and its renewal time.
+type managedCertificate struct {
+	certificate *tls.Certificate
+	renewAt     time.Time
+}
+
+// NewCertificateManager creates a certificate manager for a source.
+func NewCertificateManager(source CertificateSource, cacheDir string) *CertificateManager {
+	return &CertificateManager{
+		CacheDir:      cacheDir,
+		CheckInterval: defaultCheckInterval,
+		Name:          "certificate",
+		RenewFraction: defaultRenewFraction,
+		Source:        source,
+	}
+}
+
+// CertificateSource returns a source issuing leaf certificates from the CA.
+func (ca *CA) CertificateSource(options CertificateOptions) CertificateSource {
+	return func() ([]byte, []byte, error) {
+		return ca.Issue(options)
+	}
+}
+
+// Certificate returns the current certificate, loading or issuing one if required.
+func (m *CertificateManager) Certificate() (*tls.Certificate, error) {
+	if current := m.current.Load(); current != nil {
+		return current.certificate, nil
+	}
+
+	if _, err := m.Renew(false); err != nil {
+		return nil, err
+	}
+
+	return m.current.Load().certificate, nil
+}
+
+// GetCertificate returns the current certificate, for use as tls.Config.GetCertificate.
+func (m *CertificateManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return m.Certificate()
+}
+
+// GetClientCertificate returns the current certificate, for use as tls.Config.GetClientCertificate.
+func (m *CertificateManager) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return m.Certificate()
+}
+
+// Renew replaces the current certificate if it is due for renewal or force is set, returning whether it was replaced.
+// A cached certificate is used in place of a new one if it is not yet due for renewal.
+func (m *CertificateManager) Renew(force bool) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.clock().Now()
+	if current := m.current.Load(); current != nil && !force && now.Before(current.renewAt) {
+		return false, nil
+	}
+
+	// Try the cache.
+	if !force {
+		if cached, err := m.loadCache(); err == nil && now.Before(cached.renewAt) {
+			m.current.Store(cached)
+			return true, nil
+		}
+	}
+
+	// Issue a new certificate.
+	if m.Source == nil {
+		return false, errors.New("certificate source not specified")
+	}
+	crtBytes, keyBytes, err := m.Source()
+	if err != nil {
+		return false, err
+	}
+	issued, err := m.parse(crtBytes, keyBytes)
+	if err != nil {
+		return false, err
+	}
+	if err = m.saveCache(crtBytes, keyBytes); err != nil {
+		return false, err
+	}
+	m.current.Store(issued)
+
+	return true, nil
+}
+
+// Run renews the certificate as required until the context is cancelled.
+// Renewals that fail or leave the certificate due for renewal are retried with exponential backoff from one second up to the check interval.
+func (m *CertificateManager) Run(ctx context.Context) error {
+	clock := m.clock()
+	checkInterval := m.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = defaultCheckInterval
+	}
+	maximumRetryDelay := max(checkInterval, minimumRetryDelay)
+	retryDelay := minimumRetryDelay
+
+	for {
+		// Check certificate.
+		renewed, err := m.Renew(false)
+		delay := checkInterval
+		if err == nil {
+			now := clock.Now()
+			current := m.current.Load()
+			if untilRenewal := current.renewAt.Sub(now); untilRenewal < delay {
+				delay = untilRenewal
+			}
+			if m.OnExpiry != nil {
+				leaf := current.certificate.Leaf
+				m.OnExpiry(CertificateExpiry{
+					NotAfter:  leaf.NotAfter,
+					NotBefore: leaf.NotBefore,
+					Remaining: leaf.NotAfter.Sub(now),
+					RenewAt:   current.renewAt,
+					Renewed:   renewed,
+				})
+			}
+		}
+
+		// Back off rather than renewing continuously.
+		if err != nil || delay <= 0 {
+			delay = retryDelay
+			retryDelay = min(2*retryDelay, maximumRetryDelay)
+		} else {
+			retryDelay = minimumRetryDelay
+		}
+
+		// Wait.
+		timer := clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// TLSConfig returns a TLS configuration that uses the managed certificate for servers and clients.
+func (m *CertificateManager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate:       m.GetCertificate,
+		GetClientCertificate: m.GetClientCertificate,
+		MinVersion:           tls.VersionTLS12,
+	}
+}
+
+// cachePaths returns the paths of the cached certificate and key.
+func (m *CertificateManager) cachePaths() (string, string) {
+	name := m.Name
+	if name == "" {
+		name = "certificate"
+	}
+
+	return filepath.Join(m.CacheDir, name+".crt"), filepath.Join(m.CacheDir, name+".key")
+}
+
+// clock returns the manager's clock.
+func (m *CertificateManager) clock() timeUtils.Clock {
+	if m.Clock == nil {
+		return timeUtils.RealClock
+	}
+
+	return m.Clock
+}
+
+// loadCache loads the cached certificate.
+func (m *CertificateManager) loadCache() (*managedCertificate, error) {
+	if m.CacheDir == "" {
+		return nil, errors.New("cache disabled")
+	}
+
+	crtPath, keyPath := m.cachePaths()
+	crtBytes, err := os.ReadFile(crtPath)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.parse(crtBytes, keyBytes)
+}
+
+// parse parses a PEM-encoded certificate and key, calculating the renewal time.
+func (m *CertificateManager) parse(crtBytes []byte, keyBytes []byte) (*managedCertificate, error) {
+	certificate, err := tls.X509KeyPair(crtBytes, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if certificate.Leaf == nil {
+		if certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	renewFraction := m.RenewFraction
+	if renewFraction <= 0 || renewFraction > 1 {
+		renewFraction = defaultRenewFraction
+	}
+	lifetime := certificate.Leaf.NotAfter.Sub(certificate.Leaf.NotBefore)
+
+	return &managedCertificate{
+		certificate: &certificate,
+		renewAt:     certificate.Leaf.NotBefore.Add(time.Duration(float64(lifetime) * renewFraction)),
+	}, nil
+}
+
+// saveCache writes a certificate and key to the cache.
+func (m *CertificateManager) saveCache(crtBytes []byte, keyBytes []byte) error {
+	if m.CacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.CacheDir, 0700); err != nil {
+		return err
+	}
+
+	crtPath, keyPath := m.cachePaths()
+	if err := os.WriteFile(keyPath, keyBytes, 0600); err != nil {
+		return err
+	}
+
+	return os.WriteFile(crtPath, crtBytes, 0644)
+}
@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCertificateManager tests CertificateManager.
+func TestCertificateManager(t *testing.T) {
+	ca, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Test Root"}})
+	assert.NoError(t, err)
+	issued := 0
+	source := ca.CertificateSource(CertificateOptions{DNSNames: []string{"localhost"}, ValidFor: time.Hour})
+	cacheDir := t.TempDir()
+	manager := NewCertificateManager(func() ([]byte, []byte, error) {
+		issued++
+		return source()
+	}, cacheDir)
+
+	// Test issuing on first use.
+	certificate, err := manager.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, issued)
+	clientCertificate, err := manager.GetClientCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, certificate, clientCertificate)
+
+	// Test serving TLS and hot-swapping.
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", manager.TLSConfig())
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake() // nolint
+				conn.Close()                 // nolint
+			}()
+		}
+	}()
+	dialSerial := func() string {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: ca.CertPool(), ServerName: "localhost"})
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.String()
+	}
+	firstSerial := dialSerial()
+	assert.Equal(t, certificate.Leaf.SerialNumber.String(), firstSerial)
+	renewed, err := manager.Renew(false)
+	assert.NoError(t, err)
+	assert.False(t, renewed, "Not yet due.")
+	renewed, err = manager.Renew(true)
+	assert.NoError(t, err)
+	assert.True(t, renewed)
+	assert.Equal(t, 2, issued)
+	assert.NotEqual(t, firstSerial, dialSerial())
+
+	// Test loading from the cache.
+	cachedManager := NewCertificateManager(source, cacheDir)
+	cachedManager.Source = nil
+	cachedCertificate, err := cachedManager.Certificate()
+	assert.NoError(t, err)
+	current, err := manager.Certificate()
+	assert.NoError(t, err)
+	assert.Equal(t, current.Leaf.SerialNumber, cachedCertificate.Leaf.SerialNumber)
+
+	// Test renewal at the default fraction of a fresh certificate's lifetime, with expiry metrics.
+	clock := timeUtils.NewFakeClock(time.Now().UTC().Truncate(time.Second))
+	var runIssued atomic.Int32
+	runManager := NewCertificateManager(func() ([]byte, []byte, error) {
+		runIssued.Add(1)
+		return ca.Issue(CertificateOptions{DNSNames: []string{"localhost"}, ValidFrom: clock.Now(), ValidFor: 3 * time.Hour})
+	}, "")
+	runManager.CheckInterval = 90 * time.Minute
+	runManager.Clock = clock
+	expiries := make(chan CertificateExpiry, 100)
+	runManager.OnExpiry = func(expiry CertificateExpiry) {
+		expiries <- expiry
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- runManager.Run(ctx)
+	}()
+	firstExpiry := <-expiries
+	assert.True(t, firstExpiry.Renewed)
+	assert.Equal(t, 3*time.Hour, firstExpiry.Remaining)
+	assert.Equal(t, clock.Now().Add(2*time.Hour), firstExpiry.RenewAt, "Renews after two thirds of the lifetime.")
+	clock.BlockUntil(1)
+	clock.Advance(90 * time.Minute)
+	secondExpiry := <-expiries
+	assert.False(t, secondExpiry.Renewed, "Checked before renewal is due.")
+	clock.BlockUntil(1)
+	assert.Equal(t, int32(1), runIssued.Load())
+	clock.Advance(30*time.Minute - time.Millisecond)
+	assert.Len(t, expiries, 0, "Waits until the renewal time.")
+	assert.Equal(t, int32(1), runIssued.Load())
+	clock.Advance(time.Millisecond)
+	thirdExpiry := <-expiries
+	assert.True(t, thirdExpiry.Renewed, "Renewed at the renewal time.")
+	assert.Equal(t, clock.Now().Add(2*time.Hour), thirdExpiry.RenewAt)
+	clock.BlockUntil(1)
+	assert.Equal(t, int32(2), runIssued.Load())
+	clock.Advance(90 * time.Minute)
+	fourthExpiry := <-expiries
+	assert.False(t, fourthExpiry.Renewed, "Next renewal scheduled in the future.")
+	clock.BlockUntil(1)
+	assert.Equal(t, int32(2), runIssued.Load())
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	// Test backoff while the certificate remains due.
+	runIssued.Store(0)
+	backoffManager := NewCertificateManager(func() ([]byte, []byte, error) {
+		runIssued.Add(1)
+		return ca.Issue(CertificateOptions{DNSNames: []string{"localhost"}, ValidFrom: clock.Now().Add(-2 * time.Hour), ValidFor: 3 * time.Hour})
+	}, "")
+	backoffManager.Clock = clock
+	backoffManager.OnExpiry = runManager.OnExpiry
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		done <- backoffManager.Run(ctx)
+	}()
+	<-expiries
+	clock.BlockUntil(1)
+	assert.Equal(t, int32(1), runIssued.Load())
+	clock.Advance(999 * time.Millisecond)
+	assert.Len(t, expiries, 0, "Waits at least one second.")
+	clock.Advance(time.Millisecond)
+	<-expiries
+	clock.BlockUntil(1)
+	assert.Equal(t, int32(2), runIssued.Load())
+	clock.Advance(time.Second)
+	assert.Len(t, expiries, 0, "Backs off.")
+	clock.Advance(time.Second)
+	<-expiries
+	clock.BlockUntil(1)
+	assert.Equal(t, int32(3), runIssued.Load())
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	// Test a missing source.
+	_, err = NewCertificateManager(nil, "").Certificate()
+	assert.Error(t, err, "Missing source.")
+}
@@ -0,0 +1,237 @@
+package crypto
+
+import (
+	baseCrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMinimumRSABits is the smallest RSA key accepted in a CSR when the policy does not specify one.
+	defaultMinimumRSABits = 2048
+)
+
+// CSRPolicy defines the certificate signing requests a CA is willing to sign.
+// The zero value only permits server and client authentication certificates without subject alternative names.
+// A subject common name must be a permitted DNS name or repeat one of the request's subject alternative names.
+type CSRPolicy struct {
+	AllowedDomains      []string           // Permitted DNS names; "*.example.com" permits any subdomain of example.com.
+	AllowedExtKeyUsages []x509.ExtKeyUsage // Permitted extended key usages; defaults to server and client authentication.
+	AllowedKeyUsages    x509.KeyUsage      // Permitted key usages; defaults to digital signature and key encipherment.
+	AllowEmailAddresses bool               // Whether email subject alternative names are permitted.
+	AllowIPAddresses    bool               // Whether IP subject alternative names are permitted.
+	AllowURIs           bool               // Whether URI subject alternative names are permitted.
+	AllowWildcards      bool               // Whether wildcard DNS names such as "*.api.example.com" are permitted, within a wildcard entry of AllowedDomains.
+	MaxValidity         time.Duration      // Longest permitted validity period; unlimited if zero.
+	MinimumRSABits      int                // Smallest permitted RSA key size; defaults to 2048.
+}
+
+// CreateCSR creates a PEM-encoded PKCS #10 certificate signing request for an existing key, using the subject and subject alternative names in options.
+func CreateCSR(privateKey baseCrypto.Signer, options CertificateOptions) ([]byte, error) {
+	template := &x509.CertificateRequest{
+		DNSNames:       options.DNSNames,
+		EmailAddresses: options.EmailAddresses,
+		IPAddresses:    options.IPAddresses,
+		Subject:        options.Subject,
+		URIs:           options.URIs,
+	}
+	derBytes, err := x509.CreateCertificateRequest(rand.Reader, template, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: derBytes}), nil
+}
+
+// ParseCSR parses a PEM- or DER-encoded certificate signing request and verifies its signature.
+func ParseCSR(data []byte) (*x509.CertificateRequest, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST" {
+			return nil, errors.New("unrecognized CSR type: " + block.Type)
+		}
+		data = block.Bytes
+	}
+
+	csr, err := x509.ParseCertificateRequest(data)
+	if err != nil {
+		return nil, err
+	}
+	if err = csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+
+	switch csr.PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, errors.New("unsupported CSR public key type")
+	}
+
+	return csr, nil
+}
+
+// SignCSR signs a certificate signing request if it satisfies policy, returning the PEM-encoded certificate and any intermediates.
+// The subject and subject alternative names come from the request; validity and key usages come from options.
+func (ca *CA) SignCSR(csr *x509.CertificateRequest, options CertificateOptions, policy CSRPolicy) ([]byte, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+
+	options.DNSNames = csr.DNSNames
+	options.EmailAddresses = csr.EmailAddresses
+	options.IPAddresses = csr.IPAddresses
+	options.Subject = csr.Subject
+	options.URIs = csr.URIs
+	if options.ValidFor == 0 && policy.MaxValidity > 0 {
+		options.ValidFor = policy.MaxValidity
+	}
+	template, err := leafTemplate(options, csr.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err = policy.check(csr, template); err != nil {
+		return nil, err
+	}
+
+	certificate, err := ca.sign(template, csr.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return ca.bundlePEM(certificate), nil
+}
+
+// check returns an error if a request and the certificate template built from it violate the policy.
+func (policy CSRPolicy) check(csr *x509.CertificateRequest, template *x509.Certificate) error {
+	// Check public key.
+	minimumRSABits := policy.MinimumRSABits
+	if minimumRSABits == 0 {
+		minimumRSABits = defaultMinimumRSABits
+	}
+	if rsaKey, ok := csr.PublicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minimumRSABits {
+		return errors.New("RSA key smaller than " + strconv.Itoa(minimumRSABits) + " bits")
+	}
+
+	// Check names.
+	for _, dnsName := range csr.DNSNames {
+		if !policy.allowsDomain(dnsName) {
+			return errors.New("DNS name not permitted: " + dnsName)
+		}
+	}
+	if len(csr.EmailAddresses) > 0 && !policy.AllowEmailAddresses {
+		return errors.New("email addresses not permitted")
+	}
+	if len(csr.IPAddresses) > 0 && !policy.AllowIPAddresses {
+		return errors.New("IP addresses not permitted")
+	}
+	if len(csr.URIs) > 0 && !policy.AllowURIs {
+		return errors.New("URIs not permitted")
+	}
+	if commonName := csr.Subject.CommonName; commonName != "" && !policy.allowsCommonName(csr, commonName) {
+		return errors.New("common name not permitted: " + commonName)
+	}
+
+	// Check validity.
+	if policy.MaxValidity > 0 && template.NotAfter.Sub(template.NotBefore) > policy.MaxValidity {
+		return errors.New("validity exceeds maximum of " + policy.MaxValidity.String())
+	}
+
+	// Check key usages.
+	allowedKeyUsages := policy.AllowedKeyUsages
+	if allowedKeyUsages == 0 {
+		allowedKeyUsages = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	}
+	if template.KeyUsage&^allowedKeyUsages != 0 {
+		return errors.New("key usage not permitted")
+	}
+	allowedExtKeyUsages := policy.AllowedExtKeyUsages
+	if len(allowedExtKeyUsages) == 0 {
+		allowedExtKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+extKeyUsageLoop:
+	for _, extKeyUsage := range template.ExtKeyUsage {
+		for _, allowedExtKeyUsage := range allowedExtKeyUsages {
+			if extKeyUsage == allowedExtKeyUsage {
+				continue extKeyUsageLoop
+			}
+		}
+		return errors.New("extended key usage not permitted: " + strconv.Itoa(int(extKeyUsage)))
+	}
+
+	return nil
+}
+
+// allowsCommonName reports whether a subject common name is permitted by the policy: it must be a permitted DNS name or one of the request's subject alternative names.
+func (policy CSRPolicy) allowsCommonName(csr *x509.CertificateRequest, commonName string) bool {
+	if policy.allowsDomain(commonName) {
+		return true
+	}
+	for _, emailAddress := range csr.EmailAddresses {
+		if commonName == emailAddress {
+			return true
+		}
+	}
+	for _, ipAddress := range csr.IPAddresses {
+		if commonName == ipAddress.String() {
+			return true
+		}
+	}
+	for _, uri := range csr.URIs {
+		if commonName == uri.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsDomain reports whether a DNS name is permitted by the policy.
+// A wildcard may only appear as the entire leftmost label, and only if the policy permits wildcards.
+func (policy CSRPolicy) allowsDomain(dnsName string) bool {
+	dnsName = strings.ToLower(strings.TrimSuffix(dnsName, "."))
+	if strings.HasPrefix(dnsName, "*.") {
+		if !policy.AllowWildcards || !isValidDNSName(dnsName[2:]) {
+			return false
+		}
+	} else if !isValidDNSName(dnsName) {
+		return false
+	}
+	for _, allowedDomain := range policy.AllowedDomains {
+		allowedDomain = strings.ToLower(strings.TrimSuffix(allowedDomain, "."))
+		if strings.HasPrefix(allowedDomain, "*.") {
+			if strings.HasSuffix(dnsName, allowedDomain[1:]) && len(dnsName) > len(allowedDomain)-1 {
+				return true
+			}
+		} else if dnsName == allowedDomain {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isValidDNSName reports whether a lowercase DNS name consists of valid labels: letters, digits and inner hyphens, of up to 63 characters each.
+func isValidDNSName(dnsName string) bool {
+	if dnsName == "" || len(dnsName) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(dnsName, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, character := range label {
+			if (character < 'a' || character > 'z') && (character < '0' || character > '9') && character != '-' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSignCSR tests CreateCSR(), ParseCSR() and CA.SignCSR().
+func TestSignCSR(t *testing.T) {
+	ca, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Test Root"}})
+	assert.NoError(t, err)
+	privateKey, err := generateCertificateKey(CertificateOptions{})
+	assert.NoError(t, err)
+	policy := CSRPolicy{
+		AllowedDomains: []string{"example.com", "*.svc.example.com"},
+		MaxValidity:    90 * 24 * time.Hour,
+	}
+
+	// Test creating and parsing a CSR.
+	csrBytes, err := CreateCSR(privateKey, CertificateOptions{
+		DNSNames: []string{"example.com", "api.svc.example.com"},
+		Subject:  pkix.Name{CommonName: "api.svc.example.com", Organization: []string{"Example"}},
+	})
+	assert.NoError(t, err)
+	csr, err := ParseCSR(csrBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, "api.svc.example.com", csr.Subject.CommonName)
+
+	// Test signing within policy.
+	crtBytes, err := ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.NoError(t, err)
+	certificates, err := parseCertificatesPEM(crtBytes)
+	assert.NoError(t, err)
+	assert.True(t, publicKeysEqual(certificates[0].PublicKey, privateKey.Public()))
+	assert.Equal(t, []string{"Example"}, certificates[0].Subject.Organization)
+	assert.Equal(t, 90*24*time.Hour, certificates[0].NotAfter.Sub(certificates[0].NotBefore))
+	_, err = certificates[0].Verify(x509.VerifyOptions{DNSName: "api.svc.example.com", Roots: ca.CertPool()})
+	assert.NoError(t, err)
+
+	// Test a domain outside policy.
+	csrBytes, err = CreateCSR(privateKey, CertificateOptions{DNSNames: []string{"evil.com"}})
+	assert.NoError(t, err)
+	csr, err = ParseCSR(csrBytes)
+	assert.NoError(t, err)
+	_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.Error(t, err, "Domain not permitted.")
+
+	// Test wildcards and malformed names, which are only permitted within a wildcard entry when the policy allows wildcards.
+	wildcardPolicy := policy
+	wildcardPolicy.AllowWildcards = true
+	for _, dnsName := range []string{"*.svc.example.com", "a*b.svc.example.com", "*a.svc.example.com", "foo..svc.example.com", "-foo.svc.example.com", "foo_bar.svc.example.com", "*.example.com"} {
+		csrBytes, err = CreateCSR(privateKey, CertificateOptions{DNSNames: []string{dnsName}})
+		assert.NoError(t, err)
+		csr, err = ParseCSR(csrBytes)
+		assert.NoError(t, err)
+		_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+		assert.Error(t, err, dnsName)
+		_, err = ca.SignCSR(csr, CertificateOptions{}, wildcardPolicy)
+		if dnsName == "*.svc.example.com" {
+			assert.NoError(t, err, dnsName)
+		} else {
+			assert.Error(t, err, dnsName)
+		}
+	}
+	csrBytes, err = CreateCSR(privateKey, CertificateOptions{DNSNames: []string{"api.svc.example.com"}, Subject: pkix.Name{CommonName: "*.svc.example.com"}})
+	assert.NoError(t, err)
+	csr, err = ParseCSR(csrBytes)
+	assert.NoError(t, err)
+	_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.Error(t, err, "Wildcard common name not permitted.")
+	_, err = ca.SignCSR(csr, CertificateOptions{}, wildcardPolicy)
+	assert.NoError(t, err)
+
+	// Test a common name outside policy.
+	csrBytes, err = CreateCSR(privateKey, CertificateOptions{DNSNames: []string{"example.com"}, Subject: pkix.Name{CommonName: "evil.com"}})
+	assert.NoError(t, err)
+	csr, err = ParseCSR(csrBytes)
+	assert.NoError(t, err)
+	_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.Error(t, err, "Common name not permitted.")
+
+	// Test the wildcard base domain and IP addresses.
+	csrBytes, err = CreateCSR(privateKey, CertificateOptions{DNSNames: []string{"svc.example.com"}})
+	assert.NoError(t, err)
+	csr, err = ParseCSR(csrBytes)
+	assert.NoError(t, err)
+	_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.Error(t, err, "Wildcard does not match base domain.")
+	csrBytes, err = CreateCSR(privateKey, CertificateOptions{DNSNames: []string{"example.com"}, IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}, Subject: pkix.Name{CommonName: "10.0.0.1"}})
+	assert.NoError(t, err)
+	csr, err = ParseCSR(csrBytes)
+	assert.NoError(t, err)
+	_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.Error(t, err, "IP addresses not permitted.")
+	policy.AllowIPAddresses = true
+	_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.NoError(t, err)
+
+	// Test validity and key usages outside policy.
+	_, err = ca.SignCSR(csr, CertificateOptions{ValidFor: 365 * 24 * time.Hour}, policy)
+	assert.Error(t, err, "Validity too long.")
+	_, err = ca.SignCSR(csr, CertificateOptions{KeyUsage: x509.KeyUsageCertSign}, policy)
+	assert.Error(t, err, "Key usage not permitted.")
+	_, err = ca.SignCSR(csr, CertificateOptions{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}}, policy)
+	assert.Error(t, err, "Extended key usage not permitted.")
+	policy.AllowedExtKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+	_, err = ca.SignCSR(csr, CertificateOptions{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}}, policy)
+	assert.NoError(t, err)
+
+	// Test a weak RSA key.
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	csrBytes, err = CreateCSR(weakKey, CertificateOptions{DNSNames: []string{"example.com"}})
+	assert.NoError(t, err)
+	csr, err = ParseCSR(csrBytes)
+	assert.NoError(t, err)
+	_, err = ca.SignCSR(csr, CertificateOptions{}, policy)
+	assert.Error(t, err, "Weak RSA key.")
+
+	// Test a corrupt CSR.
+	_, err = ParseCSR([]byte("-----BEGIN CERTIFICATE REQUEST-----\nAAAA\n-----END CERTIFICATE REQUEST-----\n"))
+	assert.Error(t, err, "Corrupt CSR.")
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -35,23 +36,13 @@ func GenerateAESKey(keySize int) (string, error) {
 
 // DecryptAES decrypts an AES-protected message using GCM.
 func DecryptAES(key string, inputData []byte) ([]byte, error) {
-	decodedKey, err := hex.DecodeString(key)
-	if err != nil {
-		return nil, err
-	}
-
 	if len(inputData) < 12 {
 		return nil, errors.New("nonce not included within encrypted data")
 	}
 
 	nonce := inputData[0:12]
 
-	block, err := aes.NewCipher(decodedKey)
-	if err != nil {
-		return nil, err
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
+	aesGCM, err := newAESGCM(key)
 	if err != nil {
 		return nil, err
 	}
@@ -66,12 +57,7 @@ func DecryptAES(key string, inputData []byte) ([]byte, error) {
 
 // EncryptAES encrypts a message with AES using GCM.
 func EncryptAES(key string, inputData []byte) ([]byte, error) {
-	decodedKey, err := hex.DecodeString(key)
-	if err != nil {
-		return nil, err
-	}
-
-	block, err := aes.NewCipher(decodedKey)
+	aesGCM, err := newAESGCM(key)
 	if err != nil {
 		return nil, err
 	}
@@ -81,16 +67,13 @@ func EncryptAES(key string, inputData []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
 	outputData := aesGCM.Seal(nil, nonce, inputData, nil)
 	return append(nonce, outputData...), nil
 }
 
-// GenerateX509Certificate generates an X509 certificate for TLS.
+// GenerateX509Certificate generates a self-signed X509 certificate for TLS.
+// ecdsaCurve selects the key type: "" or "rsa", "p256", "p384", "p521" or "ed25519"; P-224 is not supported by X509 certificates. The private key is returned as a PKCS #8 PEM block.
+// To issue certificates signed by a certificate authority, use CA.
 // Based on https://golang.org/src/crypto/tls/generate_cert.go.
 func GenerateX509Certificate(hostname string, organizationName string, validFrom time.Time, validFor time.Duration, isCertAuthority bool, rsaBits int, ecdsaCurve string) ([]byte, []byte, error) {
 	// Validate inputs.
@@ -102,22 +85,7 @@ func GenerateX509Certificate(hostname string, organizationName string, validFrom
 	}
 
 	// Generate private key.
-	var privateKey interface{}
-	var err error
-	switch strings.ToLower(ecdsaCurve) {
-	case "", "rsa":
-		privateKey, err = rsa.GenerateKey(rand.Reader, rsaBits)
-	case "p224":
-		privateKey, err = ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
-	case "p256":
-		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	case "p384":
-		privateKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
-	case "p521":
-		privateKey, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
-	default:
-		return nil, nil, errors.New("unrecognized elliptic curve: " + ecdsaCurve)
-	}
+	privateKey, err := generatePrivateKey(rsaBits, ecdsaCurve)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -126,8 +94,7 @@ func GenerateX509Certificate(hostname string, organizationName string, validFrom
 	validTo := validFrom.Add(validFor)
 
 	// Generate serial number.
-	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	serialNumber, err := generateSerialNumber()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -171,20 +138,56 @@ func GenerateX509Certificate(hostname string, organizationName string, validFrom
 	return crtBuffer.Bytes(), keyBuffer.Bytes(), nil
 }
 
-// pemBlockForKey returns a PEM-encoded key.
-func pemBlockForKey(priv interface{}) (*pem.Block, error) {
-	switch k := priv.(type) {
-	case *rsa.PrivateKey:
-		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}, nil
-	case *ecdsa.PrivateKey:
-		b, err := x509.MarshalECPrivateKey(k)
-		if err != nil {
-			return nil, err
-		}
-		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}, nil
+// generatePrivateKey generates an RSA key, or an ECDSA or Ed25519 key if a curve is specified.
+func generatePrivateKey(rsaBits int, ecdsaCurve string) (interface{}, error) {
+	switch strings.ToLower(ecdsaCurve) {
+	case "", "rsa":
+		return rsa.GenerateKey(rand.Reader, rsaBits)
+	case "p224":
+		return nil, errors.New("elliptic curve not supported for certificates: " + ecdsaCurve)
+	case "p256":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "p384":
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "p521":
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "ed25519":
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
 	default:
-		return nil, errors.New("unknown key type")
+		return nil, errors.New("unrecognized elliptic curve: " + ecdsaCurve)
+	}
+}
+
+// generateSerialNumber generates a random 128-bit certificate serial number.
+func generateSerialNumber() (*big.Int, error) {
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	return rand.Int(rand.Reader, serialNumberLimit)
+}
+
+// newAESGCM returns an AES-GCM cipher for a hex-encoded key.
+func newAESGCM(key string) (cipher.AEAD, error) {
+	decodedKey, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(decodedKey)
+	if err != nil {
+		return nil, err
 	}
+
+	return cipher.NewGCM(block)
+}
+
+// pemBlockForKey returns a PEM-encoded PKCS #8 key.
+func pemBlockForKey(priv interface{}) (*pem.Block, error) {
+	b, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pem.Block{Type: "PRIVATE KEY", Bytes: b}, nil
 }
 
 // publicKey returns the public key associated with a private key.
@@ -194,6 +197,8 @@ func publicKey(priv interface{}) interface{} {
 		return &k.PublicKey
 	case *ecdsa.PrivateKey:
 		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
 	default:
 		return nil
 	}
@@ -0,0 +1,312 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// keyringFileVersion is the version of the keyring file format.
+	keyringFileVersion = 1
+
+	// keyringAssociatedData binds keyring file contents to their purpose.
+	keyringAssociatedData = "keyring"
+)
+
+// Keyring holds named AES keys, one of which is the primary key used for encryption.
+type Keyring struct {
+	keys    map[string]string
+	lock    sync.RWMutex
+	primary string
+}
+
+// keyringContents defines the plaintext contents of a keyring file.
+type keyringContents struct {
+	Keys    map[string]string `json:"keys"`
+	Primary string            `json:"primary"`
+}
+
+// keyringFile defines the on-disk keyring format.
+type keyringFile struct {
+	Data    []byte    `json:"data"`
+	KDF     KDFParams `json:"kdf"`
+	Salt    []byte    `json:"salt"`
+	Version int       `json:"version"`
+}
+
+// NewKeyring creates an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: make(map[string]string),
+	}
+}
+
+// LoadKeyring loads a keyring from a file protected by a passphrase.
+func LoadKeyring(path string, passphrase string) (*Keyring, error) {
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyringFile
+	if err = jsoniter.Unmarshal(fileBytes, &file); err != nil {
+		return nil, err
+	}
+	if file.Version != keyringFileVersion {
+		return nil, errors.New("unsupported keyring version")
+	}
+
+	fileKey, err := DeriveAESKey(passphrase, file.Salt, file.KDF)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, _, err := DecryptAESEnvelope(fileKey, file.Data, []byte(keyringAssociatedData))
+	if err != nil {
+		return nil, errors.New("unable to decrypt keyring; incorrect passphrase or corrupt file")
+	}
+
+	var contents keyringContents
+	if err = jsoniter.Unmarshal(plaintext, &contents); err != nil {
+		return nil, err
+	}
+
+	keyring := NewKeyring()
+	for keyID, key := range contents.Keys {
+		if err = keyring.Add(keyID, key); err != nil {
+			return nil, err
+		}
+	}
+	if contents.Primary != "" {
+		if err = keyring.SetPrimary(contents.Primary); err != nil {
+			return nil, err
+		}
+	}
+
+	return keyring, nil
+}
+
+// Add adds a hex-encoded AES key to the keyring. The first key added becomes the primary key.
+func (k *Keyring) Add(keyID string, key string) error {
+	if keyID == "" {
+		return errors.New("key identifier not specified")
+	}
+	if len(keyID) > aesEnvelopeMaxKeyIDLength {
+		return errors.New("key identifier too long")
+	}
+	if _, err := newAESGCM(key); err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[keyID]; ok {
+		return errors.New("key already exists: " + keyID)
+	}
+	k.keys[keyID] = key
+	if k.primary == "" {
+		k.primary = keyID
+	}
+
+	return nil
+}
+
+// Decrypt decrypts a message encrypted by any key in the keyring, returning the identifier of the key used.
+// Messages in the raw format produced by EncryptAES are decrypted by trying each key in turn.
+func (k *Keyring) Decrypt(inputData []byte, associatedData []byte) ([]byte, string, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	// Use the key named in the envelope.
+	if keyID, err := AESEnvelopeKeyID(inputData); err == nil {
+		if key, ok := k.keys[keyID]; ok {
+			outputData, _, err := DecryptAESEnvelope(key, inputData, associatedData)
+			if err != nil {
+				return nil, "", err
+			}
+
+			return outputData, keyID, nil
+		}
+	}
+
+	// Try each key.
+	for _, keyID := range k.sortedKeyIDs() {
+		if outputData, _, err := DecryptAESEnvelope(k.keys[keyID], inputData, associatedData); err == nil {
+			return outputData, keyID, nil
+		}
+	}
+
+	return nil, "", errors.New("no key in keyring could decrypt message")
+}
+
+// Encrypt encrypts a message with the primary key.
+func (k *Keyring) Encrypt(inputData []byte, associatedData []byte) ([]byte, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	if k.primary == "" {
+		return nil, errors.New("keyring has no primary key")
+	}
+
+	return EncryptAESEnvelope(k.keys[k.primary], k.primary, inputData, associatedData)
+}
+
+// KeyIDs returns the identifiers of all keys in the keyring, sorted.
+func (k *Keyring) KeyIDs() []string {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return k.sortedKeyIDs()
+}
+
+// Primary returns the identifier of the primary key.
+func (k *Keyring) Primary() string {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return k.primary
+}
+
+// Remove removes a key from the keyring. The primary key cannot be removed.
+func (k *Keyring) Remove(keyID string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[keyID]; !ok {
+		return errors.New("key not found: " + keyID)
+	}
+	if keyID == k.primary {
+		return errors.New("primary key cannot be removed")
+	}
+	delete(k.keys, keyID)
+
+	return nil
+}
+
+// Rewrap re-encrypts a message under the primary key. Messages already encrypted with the primary key are returned unchanged.
+func (k *Keyring) Rewrap(inputData []byte, associatedData []byte) (outputData []byte, rewrapped bool, err error) {
+	plaintext, keyID, err := k.Decrypt(inputData, associatedData)
+	if err != nil {
+		return nil, false, err
+	}
+	if envelopeKeyID, err := AESEnvelopeKeyID(inputData); err == nil && envelopeKeyID == keyID && keyID == k.Primary() {
+		return inputData, false, nil
+	}
+
+	outputData, err = k.Encrypt(plaintext, associatedData)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return outputData, true, nil
+}
+
+// Rotate generates a new key and makes it the primary key.
+func (k *Keyring) Rotate(keyID string, keySize int) error {
+	key, err := GenerateAESKey(keySize)
+	if err != nil {
+		return err
+	}
+	if err = k.Add(keyID, key); err != nil {
+		return err
+	}
+
+	return k.SetPrimary(keyID)
+}
+
+// Save writes the keyring to a file protected by a passphrase.
+func (k *Keyring) Save(path string, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("passphrase not specified")
+	}
+
+	k.lock.RLock()
+	contents := keyringContents{
+		Keys:    make(map[string]string, len(k.keys)),
+		Primary: k.primary,
+	}
+	for keyID, key := range k.keys {
+		contents.Keys[keyID] = key
+	}
+	k.lock.RUnlock()
+
+	plaintext, err := jsoniter.Marshal(contents)
+	if err != nil {
+		return err
+	}
+
+	// Encrypt contents under a passphrase-derived key.
+	file := keyringFile{
+		KDF:     DefaultArgon2idParams,
+		Salt:    make([]byte, 16),
+		Version: keyringFileVersion,
+	}
+	if _, err = io.ReadFull(rand.Reader, file.Salt); err != nil {
+		return err
+	}
+	fileKey, err := DeriveAESKey(passphrase, file.Salt, file.KDF)
+	if err != nil {
+		return err
+	}
+	file.Data, err = EncryptAESEnvelope(fileKey, "", plaintext, []byte(keyringAssociatedData))
+	if err != nil {
+		return err
+	}
+	fileBytes, err := jsoniter.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	// Write atomically.
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	if _, err = tempFile.Write(fileBytes); err != nil {
+		tempFile.Close()    // nolint
+		os.Remove(tempPath) // nolint
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		os.Remove(tempPath) // nolint
+		return err
+	}
+	if err = os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath) // nolint
+		return err
+	}
+
+	return nil
+}
+
+// SetPrimary sets the key used for encryption.
+func (k *Keyring) SetPrimary(keyID string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[keyID]; !ok {
+		return errors.New("key not found: " + keyID)
+	}
+	k.primary = keyID
+
+	return nil
+}
+
+// sortedKeyIDs returns the key identifiers, sorted. The caller must hold the lock.
+func (k *Keyring) sortedKeyIDs() []string {
+	keyIDs := make([]string, 0, len(k.keys))
+	for keyID := range k.keys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+
+	return keyIDs
+}
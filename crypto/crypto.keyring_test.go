@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKeyring tests Keyring.
+func TestKeyring(t *testing.T) {
+	keyring := NewKeyring()
+	plaintext := []byte("sample message")
+
+	// Test encrypting without keys.
+	_, err := keyring.Encrypt(plaintext, nil)
+	assert.Error(t, err, "No primary key.")
+
+	// Test encrypting with the primary key.
+	oldKey, err := GenerateAESKey(32)
+	assert.NoError(t, err)
+	assert.NoError(t, keyring.Add("2023", oldKey))
+	assert.Equal(t, "2023", keyring.Primary())
+	oldEncrypted, err := keyring.Encrypt(plaintext, []byte("record-1"))
+	assert.NoError(t, err)
+	legacyEncrypted, err := EncryptAES(oldKey, plaintext)
+	assert.NoError(t, err)
+
+	// Test rotation.
+	assert.NoError(t, keyring.Rotate("2024", 32))
+	assert.Equal(t, "2024", keyring.Primary())
+	assert.Equal(t, []string{"2023", "2024"}, keyring.KeyIDs())
+	assert.Error(t, keyring.Add("2024", oldKey), "Duplicate key.")
+	assert.Error(t, keyring.Remove("2024"), "Removing primary key.")
+
+	// Test decrypting with an older key.
+	decrypted, keyID, err := keyring.Decrypt(oldEncrypted, []byte("record-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.Equal(t, "2023", keyID)
+	_, _, err = keyring.Decrypt(oldEncrypted, []byte("record-2"))
+	assert.Error(t, err, "Mismatched associated data.")
+
+	// Test decrypting the raw format.
+	decrypted, keyID, err = keyring.Decrypt(legacyEncrypted, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.Equal(t, "2023", keyID)
+
+	// Test rewrapping.
+	rewrapped, changed, err := keyring.Rewrap(oldEncrypted, []byte("record-1"))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	keyID, err = AESEnvelopeKeyID(rewrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024", keyID)
+	unchanged, changed, err := keyring.Rewrap(rewrapped, []byte("record-1"))
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, rewrapped, unchanged)
+	rewrappedLegacy, changed, err := keyring.Rewrap(legacyEncrypted, nil)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// Test saving and loading.
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	assert.NoError(t, keyring.Save(path, "correct horse"))
+	loaded, err := LoadKeyring(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024", loaded.Primary())
+	assert.Equal(t, []string{"2023", "2024"}, loaded.KeyIDs())
+	decrypted, _, err = loaded.Decrypt(rewrappedLegacy, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	_, err = LoadKeyring(path, "wrong passphrase")
+	assert.Error(t, err, "Wrong passphrase.")
+
+	// Test loading a file with excessive key derivation costs.
+	fileBytes, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var file keyringFile
+	assert.NoError(t, jsoniter.Unmarshal(fileBytes, &file))
+	for _, params := range []KDFParams{
+		{Algorithm: KDFArgon2id, Memory: 1<<32 - 1, Threads: 4, Time: 3},
+		{Algorithm: KDFArgon2id, Memory: 64 * 1024, Threads: 4, Time: 1<<32 - 1},
+		{Algorithm: KDFArgon2id, Memory: 64 * 1024, Threads: 255, Time: 3},
+	} {
+		file.KDF = params
+		fileBytes, err = jsoniter.Marshal(file)
+		assert.NoError(t, err)
+		excessivePath := filepath.Join(t.TempDir(), "excessive.json")
+		assert.NoError(t, os.WriteFile(excessivePath, fileBytes, 0600))
+		_, err = LoadKeyring(excessivePath, "correct horse")
+		assert.Error(t, err, "Excessive key derivation parameters.")
+	}
+
+	// Test removing a retired key.
+	assert.NoError(t, loaded.Remove("2023"))
+	_, _, err = loaded.Decrypt(oldEncrypted, []byte("record-1"))
+	assert.Error(t, err, "Removed key.")
+}
@@ -0,0 +1,192 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KDFArgon2id selects Argon2id key derivation.
+	KDFArgon2id = "argon2id"
+
+	// KDFScrypt selects scrypt key derivation.
+	KDFScrypt = "scrypt"
+
+	// maximumArgon2idMemory is the largest Argon2id memory cost accepted, in KiB.
+	maximumArgon2idMemory = 1024 * 1024
+
+	// maximumArgon2idThreads is the largest Argon2id parallelism accepted.
+	maximumArgon2idThreads = 64
+
+	// maximumArgon2idTime is the largest Argon2id number of passes accepted.
+	maximumArgon2idTime = 100
+
+	// maximumScryptMemory is the largest scrypt memory cost accepted, in bytes.
+	maximumScryptMemory = 1024 * 1024 * 1024
+
+	// maximumScryptParallelism is the largest scrypt parallelization accepted.
+	maximumScryptParallelism = 16
+
+	// minimumSaltLength is the shortest salt accepted for key derivation.
+	minimumSaltLength = 8
+
+	// passwordSaltLength is the length of salts generated by HashPassword.
+	passwordSaltLength = 16
+)
+
+// KDFParams defines the algorithm and cost parameters for passphrase-based key derivation.
+type KDFParams struct {
+	Algorithm string `json:"algorithm"`           // KDFArgon2id or KDFScrypt.
+	KeyLength int    `json:"keyLength,omitempty"` // Derived key length in bytes; defaults to 32.
+	Memory    uint32 `json:"memory,omitempty"`    // Argon2id memory cost in KiB.
+	N         int    `json:"n,omitempty"`         // scrypt CPU/memory cost; must be a power of two.
+	P         int    `json:"p,omitempty"`         // scrypt parallelization.
+	R         int    `json:"r,omitempty"`         // scrypt block size.
+	Threads   uint8  `json:"threads,omitempty"`   // Argon2id parallelism.
+	Time      uint32 `json:"time,omitempty"`      // Argon2id number of passes.
+}
+
+// Recommended key derivation parameters.
+var (
+	DefaultArgon2idParams = KDFParams{Algorithm: KDFArgon2id, KeyLength: 32, Memory: 64 * 1024, Threads: 4, Time: 3}
+	DefaultScryptParams   = KDFParams{Algorithm: KDFScrypt, KeyLength: 32, N: 32768, P: 1, R: 8}
+)
+
+// DeriveAESKey derives a hex-encoded AES key from a passphrase, usable with EncryptAES. The key length must be 16, 24 or 32 bytes.
+func DeriveAESKey(passphrase string, salt []byte, params KDFParams) (string, error) {
+	if len(salt) < minimumSaltLength {
+		return "", errors.New("salt too short")
+	}
+	switch params.KeyLength {
+	case 0, 16, 24, 32:
+	default:
+		return "", errors.New("AES key length must be 16, 24 or 32 bytes")
+	}
+
+	key, err := deriveKey([]byte(passphrase), salt, params)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
+
+// HashPassword hashes a password with a random salt, returning a self-describing PHC-format string.
+func HashPassword(password string, params KDFParams) (string, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+
+	hash, err := deriveKey([]byte(password), salt, params)
+	if err != nil {
+		return "", err
+	}
+
+	encodedSalt := base64.RawStdEncoding.EncodeToString(salt)
+	encodedHash := base64.RawStdEncoding.EncodeToString(hash)
+	switch params.Algorithm {
+	case KDFArgon2id:
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.Memory, params.Time, params.Threads, encodedSalt, encodedHash), nil
+	default:
+		return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", bits.Len(uint(params.N))-1, params.R, params.P, encodedSalt, encodedHash), nil
+	}
+}
+
+// VerifyPassword checks a password against a PHC-format string produced by HashPassword, in constant time.
+// An error is only returned if the encoded hash is malformed.
+func VerifyPassword(password string, encodedHash string) (bool, error) {
+	params, salt, hash, err := parsePasswordHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	computedHash, err := deriveKey([]byte(password), salt, params)
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(hash, computedHash) == 1, nil
+}
+
+// deriveKey derives a key from a secret using the specified parameters.
+func deriveKey(secret []byte, salt []byte, params KDFParams) ([]byte, error) {
+	keyLength := params.KeyLength
+	if keyLength == 0 {
+		keyLength = 32
+	}
+	if keyLength < 16 {
+		return nil, errors.New("key length too short")
+	}
+
+	switch params.Algorithm {
+	case KDFArgon2id:
+		if params.Time < 1 || params.Memory < 8*uint32(params.Threads) || params.Threads < 1 {
+			return nil, errors.New("invalid argon2id parameters")
+		}
+		if params.Memory > maximumArgon2idMemory || params.Threads > maximumArgon2idThreads || params.Time > maximumArgon2idTime {
+			return nil, errors.New("argon2id parameters exceed maximum")
+		}
+		return argon2.IDKey(secret, salt, params.Time, params.Memory, params.Threads, uint32(keyLength)), nil
+	case KDFScrypt:
+		if params.N > 1 && params.R > 0 && (params.R > maximumScryptMemory/128/params.N || params.P > maximumScryptParallelism) {
+			return nil, errors.New("scrypt parameters exceed maximum")
+		}
+		return scrypt.Key(secret, salt, params.N, params.R, params.P, keyLength)
+	default:
+		return nil, errors.New("unsupported key derivation algorithm: " + params.Algorithm)
+	}
+}
+
+// parsePasswordHash parses a PHC-format password hash.
+func parsePasswordHash(encodedHash string) (params KDFParams, salt []byte, hash []byte, err error) {
+	parts := strings.Split(encodedHash, "$")
+	switch {
+	case len(parts) == 6 && parts[1] == KDFArgon2id:
+		var version int
+		if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+			return params, nil, nil, errors.New("invalid password hash version")
+		}
+		if version != argon2.Version {
+			return params, nil, nil, errors.New("unsupported argon2 version")
+		}
+		params.Algorithm = KDFArgon2id
+		if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+			return params, nil, nil, errors.New("invalid password hash parameters")
+		}
+		parts = parts[4:]
+	case len(parts) == 5 && parts[1] == KDFScrypt:
+		var logN int
+		params.Algorithm = KDFScrypt
+		if _, err = fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &logN, &params.R, &params.P); err != nil {
+			return params, nil, nil, errors.New("invalid password hash parameters")
+		}
+		if logN < 1 || logN > 31 {
+			return params, nil, nil, errors.New("invalid password hash parameters")
+		}
+		params.N = 1 << uint(logN)
+		parts = parts[3:]
+	default:
+		return params, nil, nil, errors.New("unrecognized password hash format")
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[0]); err != nil {
+		return params, nil, nil, err
+	}
+	if hash, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return params, nil, nil, err
+	}
+	params.KeyLength = len(hash)
+
+	return params, salt, hash, nil
+}
@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Inexpensive parameters for testing.
+var (
+	testArgon2idParams = KDFParams{Algorithm: KDFArgon2id, Memory: 1024, Threads: 1, Time: 1}
+	testScryptParams   = KDFParams{Algorithm: KDFScrypt, N: 1024, P: 1, R: 8}
+)
+
+// TestDeriveAESKey tests DeriveAESKey().
+func TestDeriveAESKey(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	for _, params := range []KDFParams{testArgon2idParams, testScryptParams} {
+		key, err := DeriveAESKey("passphrase", salt, params)
+		assert.NoError(t, err)
+		assert.Len(t, key, 64)
+
+		// Derivation is deterministic.
+		sameKey, err := DeriveAESKey("passphrase", salt, params)
+		assert.NoError(t, err)
+		assert.Equal(t, key, sameKey)
+		otherKey, err := DeriveAESKey("other passphrase", salt, params)
+		assert.NoError(t, err)
+		assert.NotEqual(t, key, otherKey)
+
+		// Keys work with EncryptAES.
+		encrypted, err := EncryptAES(key, []byte("sample message"))
+		assert.NoError(t, err)
+		decrypted, err := DecryptAES(key, encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("sample message"), decrypted)
+	}
+
+	// Test invalid inputs.
+	_, err := DeriveAESKey("passphrase", []byte("short"), testArgon2idParams)
+	assert.Error(t, err, "Short salt.")
+	_, err = DeriveAESKey("passphrase", salt, KDFParams{Algorithm: "md5"})
+	assert.Error(t, err, "Unknown algorithm.")
+	_, err = DeriveAESKey("passphrase", salt, KDFParams{Algorithm: KDFScrypt, N: 1000, P: 1, R: 8})
+	assert.Error(t, err, "Invalid scrypt cost.")
+	for _, keyLength := range []int{8, 20, 48, 64} {
+		params := testArgon2idParams
+		params.KeyLength = keyLength
+		_, err = DeriveAESKey("passphrase", salt, params)
+		assert.Error(t, err, "Invalid AES key length.")
+	}
+	params := testArgon2idParams
+	params.KeyLength = 16
+	key, err := DeriveAESKey("passphrase", salt, params)
+	assert.NoError(t, err)
+	assert.Len(t, key, 32, "Hex-encoded 16-byte key.")
+}
+
+// TestHashPassword tests HashPassword() and VerifyPassword().
+func TestHashPassword(t *testing.T) {
+	prefixes := map[string]string{
+		KDFArgon2id: "$argon2id$v=19$m=1024,t=1,p=1$",
+		KDFScrypt:   "$scrypt$ln=10,r=8,p=1$",
+	}
+	for _, params := range []KDFParams{testArgon2idParams, testScryptParams} {
+		encodedHash, err := HashPassword("hunter2", params)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(encodedHash, prefixes[params.Algorithm]), encodedHash)
+
+		ok, err := VerifyPassword("hunter2", encodedHash)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = VerifyPassword("hunter3", encodedHash)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		// Salts are random.
+		otherHash, err := HashPassword("hunter2", params)
+		assert.NoError(t, err)
+		assert.NotEqual(t, encodedHash, otherHash)
+	}
+
+	// Test malformed hashes.
+	_, err := VerifyPassword("hunter2", "$md5$abc$def")
+	assert.Error(t, err, "Unknown algorithm.")
+	_, err = VerifyPassword("hunter2", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA")
+	assert.Error(t, err, "Unsupported version.")
+	_, err = VerifyPassword("hunter2", "$scrypt$ln=99,r=8,p=1$c2FsdA$aGFzaA")
+	assert.Error(t, err, "Invalid cost.")
+
+	// Test excessive costs, which must be rejected before deriving a key.
+	for _, encodedHash := range []string{
+		"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdHNhbHQ$AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+		"$argon2id$v=19$m=1024,t=4294967295,p=1$c2FsdHNhbHQ$AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+		"$argon2id$v=19$m=65536,t=1,p=255$c2FsdHNhbHQ$AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+		"$scrypt$ln=31,r=8,p=1$c2FsdHNhbHQ$AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+		"$scrypt$ln=10,r=1048576,p=1$c2FsdHNhbHQ$AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+		"$scrypt$ln=10,r=8,p=1024$c2FsdHNhbHQ$AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+	} {
+		_, err = VerifyPassword("hunter2", encodedHash)
+		assert.Error(t, err, encodedHash)
+	}
+}
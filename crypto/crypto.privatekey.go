@@ -0,0 +1,253 @@
+package crypto
+
+import (
+	"bytes"
+	baseCrypto "crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// pbkdf2Iterations is the PBKDF2 iteration count used when encrypting private keys.
+	pbkdf2Iterations = 600000
+
+	// pbkdf2MaximumIterations is the largest PBKDF2 iteration count accepted when decrypting private keys.
+	pbkdf2MaximumIterations = 10000000
+)
+
+// Object identifiers for PKCS #5 password-based encryption.
+var (
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+)
+
+// encryptedPrivateKeyInfo defines a PKCS #8 EncryptedPrivateKeyInfo structure.
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+// pbes2Params defines PKCS #5 PBES2 parameters.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params defines PKCS #5 PBKDF2 parameters.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// MarshalPrivateKeyPEM returns a PEM-encoded PKCS #8 private key.
+// If passphrase is not empty, the key is encrypted with PBES2 (PBKDF2-HMAC-SHA256 and AES-256-CBC) as an "ENCRYPTED PRIVATE KEY" block.
+func MarshalPrivateKeyPEM(privateKey interface{}, passphrase string) ([]byte, error) {
+	block, err := pemBlockForKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return pem.EncodeToMemory(block), nil
+	}
+
+	// Derive key.
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	key := pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, 32, sha256.New)
+
+	// Encrypt with PKCS #7 padding.
+	aesBlock, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(block.Bytes)%aes.BlockSize
+	encryptedData := append(block.Bytes, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(aesBlock, iv).CryptBlocks(encryptedData, encryptedData)
+
+	// Encode parameters.
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pbkdf2Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	schemeParams, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	derBytes, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		EncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: schemeParams}},
+		EncryptedData:       encryptedData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: derBytes}), nil
+}
+
+// ParsePrivateKey parses a PEM-encoded private key in PKCS #1, SEC 1 or PKCS #8 form.
+// Keys encrypted with PKCS #8 PBES2 or legacy PEM encryption are decrypted with passphrase.
+func ParsePrivateKey(data []byte, passphrase string) (baseCrypto.Signer, error) {
+	var block *pem.Block
+	for {
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no private key found")
+		}
+		if block.Type != "CERTIFICATE" {
+			break
+		}
+	}
+
+	// Decrypt.
+	derBytes := block.Bytes
+	var err error
+	if x509.IsEncryptedPEMBlock(block) { // nolint: staticcheck
+		if passphrase == "" {
+			return nil, errors.New("private key is encrypted")
+		}
+		if derBytes, err = x509.DecryptPEMBlock(block, []byte(passphrase)); err != nil { // nolint: staticcheck
+			return nil, err
+		}
+	}
+
+	var privateKey interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(derBytes)
+	case "EC PRIVATE KEY":
+		privateKey, err = x509.ParseECPrivateKey(derBytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(derBytes)
+	case "ENCRYPTED PRIVATE KEY":
+		if passphrase == "" {
+			return nil, errors.New("private key is encrypted")
+		}
+		if derBytes, err = decryptPKCS8(derBytes, passphrase); err != nil {
+			return nil, err
+		}
+		privateKey, err = x509.ParsePKCS8PrivateKey(derBytes)
+	default:
+		return nil, errors.New("unrecognized private key type: " + block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(baseCrypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+
+	return signer, nil
+}
+
+// decryptPKCS8 decrypts a PBES2-encrypted PKCS #8 EncryptedPrivateKeyInfo structure.
+func decryptPKCS8(derBytes []byte, passphrase string) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(derBytes, &info); err != nil {
+		return nil, err
+	}
+	if !info.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, errors.New("unsupported private key encryption algorithm")
+	}
+
+	var schemeParams pbes2Params
+	if _, err := asn1.Unmarshal(info.EncryptionAlgorithm.Parameters.FullBytes, &schemeParams); err != nil {
+		return nil, err
+	}
+	if !schemeParams.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errors.New("unsupported private key derivation function")
+	}
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(schemeParams.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, err
+	}
+	if kdfParams.IterationCount < 1 || kdfParams.IterationCount > pbkdf2MaximumIterations {
+		return nil, errors.New("invalid PBKDF2 iteration count")
+	}
+
+	// Select hash and cipher.
+	var prf func() hash.Hash
+	switch {
+	case len(kdfParams.PRF.Algorithm) == 0, kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, errors.New("unsupported PBKDF2 pseudorandom function")
+	}
+	var keyLength int
+	switch {
+	case schemeParams.EncryptionScheme.Algorithm.Equal(oidAES128CBC):
+		keyLength = 16
+	case schemeParams.EncryptionScheme.Algorithm.Equal(oidAES192CBC):
+		keyLength = 24
+	case schemeParams.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+		keyLength = 32
+	default:
+		return nil, errors.New("unsupported private key cipher")
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(schemeParams.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, errors.New("malformed encrypted private key")
+	}
+
+	// Decrypt and remove padding.
+	key := pbkdf2.Key([]byte(passphrase), kdfParams.Salt, kdfParams.IterationCount, keyLength, prf)
+	aesBlock, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	decryptedData := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(aesBlock, iv).CryptBlocks(decryptedData, info.EncryptedData)
+	padding := int(decryptedData[len(decryptedData)-1])
+	if padding < 1 || padding > aes.BlockSize || subtle.ConstantTimeCompare(decryptedData[len(decryptedData)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) != 1 {
+		return nil, errors.New("incorrect passphrase or corrupt private key")
+	}
+
+	return decryptedData[:len(decryptedData)-padding], nil
+}
@@ -0,0 +1,256 @@
+package crypto
+
+import (
+	"bytes"
+	baseCrypto "crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// defaultCRLValidity is the time until the next CRL update when none is specified.
+	defaultCRLValidity = 24 * time.Hour
+
+	// defaultOCSPValidity is the time until the next OCSP update when none is specified.
+	defaultOCSPValidity = time.Hour
+
+	// maximumOCSPRequestSize is the largest OCSP request body accepted.
+	maximumOCSPRequestSize = 64 * 1024
+)
+
+// revocation defines a revoked certificate.
+type revocation struct {
+	reason    int
+	revokedAt time.Time
+	serial    *big.Int
+}
+
+// OCSPResponder answers OCSP requests for certificates issued by a CA.
+type OCSPResponder struct {
+	CA       *CA           // Issuing certificate authority, which also signs responses.
+	ValidFor time.Duration // Time until the next update; defaults to one hour.
+}
+
+// ParseCRL parses a PEM- or DER-encoded certificate revocation list, verifying its signature if issuer is not nil.
+func ParseCRL(data []byte, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "X509 CRL" {
+			return nil, errors.New("unrecognized CRL type: " + block.Type)
+		}
+		data = block.Bytes
+	}
+
+	revocationList, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, err
+	}
+	if issuer != nil {
+		if err = revocationList.CheckSignatureFrom(issuer); err != nil {
+			return nil, err
+		}
+	}
+
+	return revocationList, nil
+}
+
+// CRLContains reports whether a certificate revocation list contains a serial number.
+func CRLContains(revocationList *x509.RevocationList, serialNumber *big.Int) bool {
+	for _, revokedCertificate := range revocationList.RevokedCertificateEntries {
+		if revokedCertificate.SerialNumber.Cmp(serialNumber) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateCRL creates a PEM-encoded certificate revocation list of all certificates revoked by the CA.
+func (ca *CA) CreateCRL(thisUpdate time.Time, validFor time.Duration) ([]byte, error) {
+	if validFor <= 0 {
+		validFor = defaultCRLValidity
+	}
+
+	ca.lock.Lock()
+	ca.crlNumber++
+	template := &x509.RevocationList{
+		NextUpdate: thisUpdate.Add(validFor),
+		Number:     big.NewInt(ca.crlNumber),
+		ThisUpdate: thisUpdate,
+	}
+	for _, revoked := range ca.revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			ReasonCode:     revoked.reason,
+			RevocationTime: revoked.revokedAt,
+			SerialNumber:   revoked.serial,
+		})
+	}
+	ca.lock.Unlock()
+
+	derBytes, err := x509.CreateRevocationList(rand.Reader, template, ca.Certificate, ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: derBytes}), nil
+}
+
+// IsIssued reports whether the CA issued a certificate, either by signing it or through MarkIssued.
+func (ca *CA) IsIssued(serialNumber *big.Int) bool {
+	ca.lock.Lock()
+	defer ca.lock.Unlock()
+
+	return ca.issued[serialNumber.String()]
+}
+
+// IsRevoked reports whether the CA has revoked a certificate, returning the reason and time of revocation.
+func (ca *CA) IsRevoked(serialNumber *big.Int) (revoked bool, reason int, revokedAt time.Time) {
+	ca.lock.Lock()
+	defer ca.lock.Unlock()
+
+	entry, ok := ca.revoked[serialNumber.String()]
+	if !ok {
+		return false, 0, time.Time{}
+	}
+
+	return true, entry.reason, entry.revokedAt
+}
+
+// Revoke revokes a certificate issued by the CA. Reason codes are defined by RFC 5280, such as ocsp.KeyCompromise.
+func (ca *CA) Revoke(serialNumber *big.Int, reason int) error {
+	if serialNumber == nil {
+		return errors.New("serial number not specified")
+	}
+	if reason < ocsp.Unspecified || reason > ocsp.AACompromise || reason == 7 {
+		return errors.New("invalid revocation reason")
+	}
+
+	ca.lock.Lock()
+	defer ca.lock.Unlock()
+
+	if ca.revoked == nil {
+		ca.revoked = make(map[string]revocation)
+	}
+	if _, ok := ca.revoked[serialNumber.String()]; !ok {
+		ca.revoked[serialNumber.String()] = revocation{
+			reason:    reason,
+			revokedAt: time.Now().UTC().Truncate(time.Second),
+			serial:    new(big.Int).Set(serialNumber),
+		}
+	}
+
+	return nil
+}
+
+// NewOCSPResponder creates an OCSP responder for a CA.
+func NewOCSPResponder(ca *CA) *OCSPResponder {
+	return &OCSPResponder{
+		CA:       ca,
+		ValidFor: defaultOCSPValidity,
+	}
+}
+
+// ServeHTTP answers OCSP requests sent by GET or POST, as described by RFC 6960.
+// GET requests carry the encoded request as the whole path; use http.StripPrefix to mount the responder below a prefix.
+// Serial numbers that the CA neither issued nor revoked are reported as unknown.
+func (r *OCSPResponder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// Read request.
+	var requestBytes []byte
+	var err error
+	switch req.Method {
+	case http.MethodGet:
+		requestBytes, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(req.URL.Path, "/"))
+	case http.MethodPost:
+		requestBytes, err = io.ReadAll(io.LimitReader(req.Body, maximumOCSPRequestSize))
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		writeOCSPResponse(w, ocsp.MalformedRequestErrorResponse)
+		return
+	}
+
+	ocspRequest, err := ocsp.ParseRequest(requestBytes)
+	if err != nil {
+		writeOCSPResponse(w, ocsp.MalformedRequestErrorResponse)
+		return
+	}
+
+	// Ensure the request is for this CA.
+	issuerNameHash, issuerKeyHash, err := ocspIssuerHashes(r.CA.Certificate, ocspRequest.HashAlgorithm)
+	if err != nil || !bytes.Equal(issuerNameHash, ocspRequest.IssuerNameHash) || !bytes.Equal(issuerKeyHash, ocspRequest.IssuerKeyHash) {
+		writeOCSPResponse(w, ocsp.UnauthorizedErrorResponse)
+		return
+	}
+
+	// Build response.
+	validFor := r.ValidFor
+	if validFor <= 0 {
+		validFor = defaultOCSPValidity
+	}
+	now := time.Now().UTC().Truncate(time.Minute)
+	template := ocsp.Response{
+		IssuerHash:   ocspRequest.HashAlgorithm,
+		NextUpdate:   now.Add(validFor),
+		SerialNumber: ocspRequest.SerialNumber,
+		Status:       ocsp.Good,
+		ThisUpdate:   now,
+	}
+	if revoked, reason, revokedAt := r.CA.IsRevoked(ocspRequest.SerialNumber); revoked {
+		template.Status = ocsp.Revoked
+		template.RevocationReason = reason
+		template.RevokedAt = revokedAt
+	} else if !r.CA.IsIssued(ocspRequest.SerialNumber) {
+		template.Status = ocsp.Unknown
+	}
+
+	responseBytes, err := ocsp.CreateResponse(r.CA.Certificate, r.CA.Certificate, template, r.CA.PrivateKey)
+	if err != nil {
+		writeOCSPResponse(w, ocsp.InternalErrorErrorResponse)
+		return
+	}
+	writeOCSPResponse(w, responseBytes)
+}
+
+// ocspIssuerHashes returns the hashes of an issuer's name and public key used to identify it in OCSP requests.
+func ocspIssuerHashes(issuer *x509.Certificate, hashAlgorithm baseCrypto.Hash) (nameHash []byte, keyHash []byte, err error) {
+	if !hashAlgorithm.Available() {
+		return nil, nil, errors.New("unsupported hash algorithm")
+	}
+
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err = asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return nil, nil, err
+	}
+
+	hash := hashAlgorithm.New()
+	hash.Write(issuer.RawSubject) // nolint
+	nameHash = hash.Sum(nil)
+	hash.Reset()
+	hash.Write(publicKeyInfo.PublicKey.RightAlign()) // nolint
+	keyHash = hash.Sum(nil)
+
+	return nameHash, keyHash, nil
+}
+
+// writeOCSPResponse writes a DER-encoded OCSP response.
+func writeOCSPResponse(w http.ResponseWriter, responseBytes []byte) {
+	w.Header().Set("Content-Type", "application/ocsp-response")
+	w.Write(responseBytes) // nolint
+}
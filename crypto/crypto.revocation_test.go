@@ -0,0 +1,141 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
+)
+
+// TestCreateCRL tests CA.CreateCRL() and ParseCRL().
+func TestCreateCRL(t *testing.T) {
+	ca, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Test Root"}})
+	assert.NoError(t, err)
+	ca.CRLDistributionPoints = []string{"http://ca.test/crl"}
+	revokedCertificate := issueTestCertificate(t, ca, "revoked.test")
+	goodCertificate := issueTestCertificate(t, ca, "good.test")
+	assert.Equal(t, []string{"http://ca.test/crl"}, goodCertificate.CRLDistributionPoints)
+
+	// Test revocation.
+	assert.NoError(t, ca.Revoke(revokedCertificate.SerialNumber, ocsp.KeyCompromise))
+	assert.Error(t, ca.Revoke(revokedCertificate.SerialNumber, 99), "Invalid reason.")
+	revoked, reason, _ := ca.IsRevoked(revokedCertificate.SerialNumber)
+	assert.True(t, revoked)
+	assert.Equal(t, ocsp.KeyCompromise, reason)
+
+	// Test creating and parsing a CRL.
+	crlBytes, err := ca.CreateCRL(time.Now(), time.Hour)
+	assert.NoError(t, err)
+	revocationList, err := ParseCRL(crlBytes, ca.Certificate)
+	assert.NoError(t, err)
+	assert.True(t, CRLContains(revocationList, revokedCertificate.SerialNumber))
+	assert.False(t, CRLContains(revocationList, goodCertificate.SerialNumber))
+	assert.Equal(t, int64(1), revocationList.Number.Int64())
+	assert.Len(t, revocationList.RevokedCertificateEntries, 1)
+	assert.Equal(t, ocsp.KeyCompromise, revocationList.RevokedCertificateEntries[0].ReasonCode)
+
+	// Test CRL numbers increase.
+	crlBytes, err = ca.CreateCRL(time.Now(), time.Hour)
+	assert.NoError(t, err)
+	revocationList, err = ParseCRL(crlBytes, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), revocationList.Number.Int64())
+
+	// Test verifying against the wrong issuer.
+	otherCA, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Other Root"}})
+	assert.NoError(t, err)
+	_, err = ParseCRL(crlBytes, otherCA.Certificate)
+	assert.Error(t, err, "Wrong issuer.")
+}
+
+// TestOCSPResponder tests OCSPResponder.
+func TestOCSPResponder(t *testing.T) {
+	ca, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Test Root"}})
+	assert.NoError(t, err)
+	server := httptest.NewServer(NewOCSPResponder(ca))
+	defer server.Close()
+	ca.OCSPServers = []string{server.URL}
+	revokedCertificate := issueTestCertificate(t, ca, "revoked.test")
+	goodCertificate := issueTestCertificate(t, ca, "good.test")
+	assert.NoError(t, ca.Revoke(revokedCertificate.SerialNumber, ocsp.Superseded))
+
+	// Test a good certificate over POST.
+	response := queryOCSP(t, http.MethodPost, server.URL, goodCertificate, ca.Certificate)
+	assert.Equal(t, ocsp.Good, response.Status)
+
+	// Test a revoked certificate over GET.
+	response = queryOCSP(t, http.MethodGet, goodCertificate.OCSPServer[0], revokedCertificate, ca.Certificate)
+	assert.Equal(t, ocsp.Revoked, response.Status)
+	assert.Equal(t, ocsp.Superseded, response.RevocationReason)
+
+	// Test a serial number the CA never issued.
+	unissuedCertificate := *goodCertificate
+	unissuedCertificate.SerialNumber = big.NewInt(12345)
+	response = queryOCSP(t, http.MethodPost, server.URL, &unissuedCertificate, ca.Certificate)
+	assert.Equal(t, ocsp.Unknown, response.Status)
+
+	// Test restoring issued serial numbers after loading a CA.
+	keyBytes, err := ca.PrivateKeyPEM()
+	assert.NoError(t, err)
+	loadedCA, err := LoadCA(ca.CertificatePEM(), keyBytes)
+	assert.NoError(t, err)
+	assert.False(t, loadedCA.IsIssued(goodCertificate.SerialNumber))
+	loadedCA.MarkIssued(goodCertificate.SerialNumber)
+	assert.True(t, loadedCA.IsIssued(goodCertificate.SerialNumber))
+
+	// Test a request for another issuer.
+	otherCA, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Other Root"}})
+	assert.NoError(t, err)
+	otherCertificate := issueTestCertificate(t, otherCA, "other.test")
+	requestBytes, err := ocsp.CreateRequest(otherCertificate, otherCA.Certificate, nil)
+	assert.NoError(t, err)
+	httpResponse, err := http.Post(server.URL, "application/ocsp-request", bytes.NewReader(requestBytes))
+	assert.NoError(t, err)
+	responseBytes, err := io.ReadAll(httpResponse.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, httpResponse.Body.Close())
+	_, err = ocsp.ParseResponse(responseBytes, ca.Certificate)
+	assert.Equal(t, ocsp.ResponseError{Status: ocsp.Unauthorized}, err)
+}
+
+// issueTestCertificate issues a server certificate and parses it.
+func issueTestCertificate(t *testing.T, ca *CA, hostname string) *x509.Certificate {
+	crtBytes, _, err := ca.IssueServerCertificate(CertificateOptions{DNSNames: []string{hostname}})
+	assert.NoError(t, err)
+	certificates, err := parseCertificatesPEM(crtBytes)
+	assert.NoError(t, err)
+
+	return certificates[0]
+}
+
+// queryOCSP sends an OCSP request and parses the response.
+func queryOCSP(t *testing.T, method string, serverURL string, certificate *x509.Certificate, issuer *x509.Certificate) *ocsp.Response {
+	requestBytes, err := ocsp.CreateRequest(certificate, issuer, nil)
+	assert.NoError(t, err)
+
+	var httpResponse *http.Response
+	if method == http.MethodGet {
+		httpResponse, err = http.Get(serverURL + "/" + url.PathEscape(base64.StdEncoding.EncodeToString(requestBytes)))
+	} else {
+		httpResponse, err = http.Post(serverURL, "application/ocsp-request", bytes.NewReader(requestBytes))
+	}
+	assert.NoError(t, err)
+	responseBytes, err := io.ReadAll(httpResponse.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, httpResponse.Body.Close())
+
+	response, err := ocsp.ParseResponseForCert(responseBytes, certificate, issuer)
+	assert.NoError(t, err)
+
+	return response
+}
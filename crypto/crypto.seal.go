@@ -0,0 +1,285 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// sealVersion is the version byte written at the start of each sealed message.
+	sealVersion = 1
+
+	// sealKeyIDSize is the size of the recipient key fingerprint in each recipient block.
+	sealKeyIDSize = 8
+
+	// sealMaximumRecipients is the largest number of recipients a sealed message can have.
+	sealMaximumRecipients = 65535
+)
+
+var (
+	// sealInfo is the HKDF context for deriving recipient wrapping keys.
+	sealInfo = []byte("github.com/bertjohnson/util/crypto seal v1")
+)
+
+// GenerateX25519Key generates an X25519 key pair for SealTo and Open, returning PEM-encoded PKIX public and PKCS #8 private keys.
+func GenerateX25519Key() (publicKeyBytes []byte, privateKeyBytes []byte, err error) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	derBytes, err := x509.MarshalPKIXPublicKey(privateKey.PublicKey())
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKeyBytes, err = MarshalPrivateKeyPEM(privateKey, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: derBytes}), privateKeyBytes, nil
+}
+
+// Open decrypts a message sealed by SealTo or SealToRecipients using a PEM-encoded X25519 or ECDSA private key.
+func Open(privateKeyBytes []byte, inputData []byte) ([]byte, error) {
+	privateKey, err := parseECDHPrivateKey(privateKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	keyID := sealKeyID(privateKey.PublicKey())
+
+	// Read header.
+	if len(inputData) < 3 || inputData[0] != sealVersion {
+		return nil, errors.New("unrecognized sealed message version")
+	}
+	recipientCount := int(binary.BigEndian.Uint16(inputData[1:3]))
+	position := 3
+	var dataKey []byte
+	for i := 0; i < recipientCount; i++ {
+		if len(inputData) < position+sealKeyIDSize+2 {
+			return nil, errors.New("sealed message truncated")
+		}
+		recipientKeyID := inputData[position : position+sealKeyIDSize]
+		position += sealKeyIDSize
+		ephemeralLength := int(binary.BigEndian.Uint16(inputData[position : position+2]))
+		position += 2
+		wrappedLength := ephemeralLength + 32 + 16
+		if len(inputData) < position+wrappedLength {
+			return nil, errors.New("sealed message truncated")
+		}
+		ephemeralBytes := inputData[position : position+ephemeralLength]
+		wrappedKey := inputData[position+ephemeralLength : position+wrappedLength]
+		position += wrappedLength
+
+		// Unwrap the data key if this block is for our key.
+		if dataKey == nil && bytes.Equal(recipientKeyID, keyID) {
+			dataKey, _ = unwrapSealKey(privateKey, ephemeralBytes, wrappedKey)
+		}
+	}
+	if dataKey == nil {
+		return nil, errors.New("message not sealed to this key")
+	}
+
+	// Decrypt payload.
+	aesGCM, err := newSealGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputData) < position+aesGCM.NonceSize() {
+		return nil, errors.New("sealed message truncated")
+	}
+	nonce := inputData[position : position+aesGCM.NonceSize()]
+
+	return aesGCM.Open(nil, nonce, inputData[position+aesGCM.NonceSize():], inputData[:position])
+}
+
+// SealTo encrypts a message to a PEM-encoded X25519 or P-256 public key or certificate, using ECDH and AES-GCM.
+func SealTo(publicKeyBytes []byte, inputData []byte) ([]byte, error) {
+	return SealToRecipients([][]byte{publicKeyBytes}, inputData)
+}
+
+// SealToRecipients encrypts a message so that any of several recipients can open it.
+// The message is encrypted once with a random data key, which is wrapped for each recipient with a key derived from an ephemeral ECDH exchange.
+func SealToRecipients(publicKeysBytes [][]byte, inputData []byte) ([]byte, error) {
+	if len(publicKeysBytes) == 0 {
+		return nil, errors.New("no recipients specified")
+	}
+	if len(publicKeysBytes) > sealMaximumRecipients {
+		return nil, errors.New("too many recipients")
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	// Write header with a block per recipient.
+	outputData := []byte{sealVersion, 0, 0}
+	binary.BigEndian.PutUint16(outputData[1:3], uint16(len(publicKeysBytes)))
+	for _, publicKeyBytes := range publicKeysBytes {
+		publicKey, err := parseECDHPublicKey(publicKeyBytes)
+		if err != nil {
+			return nil, err
+		}
+		ephemeralKey, err := publicKey.Curve().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		sharedSecret, err := ephemeralKey.ECDH(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		wrappingGCM, err := sealWrappingGCM(sharedSecret, ephemeralKey.PublicKey(), publicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		ephemeralBytes := ephemeralKey.PublicKey().Bytes()
+		outputData = append(outputData, sealKeyID(publicKey)...)
+		outputData = binary.BigEndian.AppendUint16(outputData, uint16(len(ephemeralBytes)))
+		outputData = append(outputData, ephemeralBytes...)
+		outputData = wrappingGCM.Seal(outputData, make([]byte, wrappingGCM.NonceSize()), dataKey, nil)
+	}
+
+	// Encrypt payload, authenticating the header.
+	aesGCM, err := newSealGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	header := outputData
+
+	return aesGCM.Seal(append(outputData, nonce...), nonce, inputData, header), nil
+}
+
+// newSealGCM returns an AES-GCM cipher for a raw key.
+func newSealGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// parseECDHPrivateKey parses a PEM-encoded X25519 or ECDSA private key for key agreement.
+func parseECDHPrivateKey(data []byte) (*ecdh.PrivateKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no private key found")
+		}
+
+		var privateKey interface{}
+		var err error
+		switch block.Type {
+		case "EC PRIVATE KEY":
+			privateKey, err = x509.ParseECPrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch k := privateKey.(type) {
+		case *ecdh.PrivateKey:
+			return k, nil
+		case *ecdsa.PrivateKey:
+			return k.ECDH()
+		default:
+			return nil, errors.New("private key does not support key agreement")
+		}
+	}
+}
+
+// parseECDHPublicKey parses a PEM-encoded X25519 or ECDSA public key, or a certificate containing one.
+func parseECDHPublicKey(data []byte) (*ecdh.PublicKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no public key found")
+		}
+
+		var publicKey interface{}
+		switch block.Type {
+		case "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			publicKey = certificate.PublicKey
+		case "PUBLIC KEY":
+			var err error
+			if publicKey, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+
+		switch k := publicKey.(type) {
+		case *ecdh.PublicKey:
+			return k, nil
+		case *ecdsa.PublicKey:
+			return k.ECDH()
+		default:
+			return nil, errors.New("public key does not support key agreement")
+		}
+	}
+}
+
+// sealKeyID returns the fingerprint identifying a recipient's public key.
+func sealKeyID(publicKey *ecdh.PublicKey) []byte {
+	digest := sha256.Sum256(publicKey.Bytes())
+	return digest[:sealKeyIDSize]
+}
+
+// sealWrappingGCM derives the AES-GCM cipher wrapping the data key for one recipient from an ECDH shared secret.
+// The derived key is bound to both the ephemeral and recipient public keys.
+func sealWrappingGCM(sharedSecret []byte, ephemeralKey *ecdh.PublicKey, recipientKey *ecdh.PublicKey) (cipher.AEAD, error) {
+	info := append(append(append([]byte{}, sealInfo...), ephemeralKey.Bytes()...), recipientKey.Bytes()...)
+	wrappingKey := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, nil, info), wrappingKey); err != nil {
+		return nil, err
+	}
+
+	return newSealGCM(wrappingKey)
+}
+
+// unwrapSealKey recovers the data key from a recipient block.
+func unwrapSealKey(privateKey *ecdh.PrivateKey, ephemeralBytes []byte, wrappedKey []byte) ([]byte, error) {
+	ephemeralKey, err := privateKey.Curve().NewPublicKey(ephemeralBytes)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := privateKey.ECDH(ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+	wrappingGCM, err := sealWrappingGCM(sharedSecret, ephemeralKey, privateKey.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	return wrappingGCM.Open(nil, make([]byte, wrappingGCM.NonceSize()), wrappedKey, nil)
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSealTo tests SealTo(), SealToRecipients() and Open().
+func TestSealTo(t *testing.T) {
+	plaintext := []byte("sample message")
+
+	// Test an X25519 round trip.
+	publicKeyBytes, privateKeyBytes, err := GenerateX25519Key()
+	assert.NoError(t, err)
+	sealed, err := SealTo(publicKeyBytes, plaintext)
+	assert.NoError(t, err)
+	opened, err := Open(privateKeyBytes, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	// Test a P-256 certificate as the recipient.
+	ca, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Test Root"}})
+	assert.NoError(t, err)
+	crtBytes, keyBytes, err := ca.Issue(CertificateOptions{DNSNames: []string{"recipient.test"}})
+	assert.NoError(t, err)
+	sealed, err = SealTo(crtBytes, plaintext)
+	assert.NoError(t, err)
+	opened, err = Open(keyBytes, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	// Test multiple recipients with different curves.
+	sealed, err = SealToRecipients([][]byte{publicKeyBytes, crtBytes}, plaintext)
+	assert.NoError(t, err)
+	opened, err = Open(privateKeyBytes, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+	opened, err = Open(keyBytes, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	// Test a key that is not a recipient.
+	_, otherKeyBytes, err := GenerateX25519Key()
+	assert.NoError(t, err)
+	_, err = Open(otherKeyBytes, sealed)
+	assert.Error(t, err, "Not a recipient.")
+
+	// Test tampering with the header and payload.
+	tampered := append([]byte{}, sealed...)
+	tampered[3] ^= 1
+	_, err = Open(privateKeyBytes, tampered)
+	assert.Error(t, err, "Tampered header.")
+	tampered = append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = Open(privateKeyBytes, tampered)
+	assert.Error(t, err, "Tampered payload.")
+	_, err = Open(privateKeyBytes, sealed[:20])
+	assert.Error(t, err, "Truncated message.")
+
+	// Test unsupported recipients.
+	rsaCrtBytes, _, err := ca.Issue(CertificateOptions{DNSNames: []string{"rsa.test"}, RSABits: 2048})
+	assert.NoError(t, err)
+	_, err = SealTo(rsaCrtBytes, plaintext)
+	assert.Error(t, err, "RSA recipient.")
+	_, err = SealToRecipients(nil, plaintext)
+	assert.Error(t, err, "No recipients.")
+	_, err = SealTo([]byte("not a key"), plaintext)
+	assert.Error(t, err, "Invalid PEM.")
+}
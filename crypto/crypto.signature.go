@@ -0,0 +1,182 @@
+package crypto
+
+import (
+	baseCrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"hash"
+	"io"
+	"time"
+)
+
+// Detached signature algorithms.
+const (
+	SignatureAlgorithmECDSA   = "ECDSA-SHA256"   // ECDSA over a SHA-256 digest.
+	SignatureAlgorithmEd25519 = "Ed25519ph"      // Ed25519 over a SHA-512 digest (RFC 8032 prehash).
+	SignatureAlgorithmRSAPSS  = "RSA-PSS-SHA256" // RSA-PSS over a SHA-256 digest, with a salt the length of the digest.
+)
+
+const (
+	// signaturePEMType is the PEM block type of a detached signature.
+	signaturePEMType = "DETACHED SIGNATURE"
+)
+
+// Sign creates a detached signature over the data read from src, using a PEM-encoded certificate chain and its unencrypted private key, such as those returned by CA.Issue with code signing usage.
+// The signature is a PEM "DETACHED SIGNATURE" block, with an "Algorithm" header, followed by the signer's certificate chain.
+func Sign(crtBytes []byte, keyBytes []byte, src io.Reader) ([]byte, error) {
+	certificates, err := parseCertificatesPEM(crtBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	privateKey, err := ParsePrivateKey(keyBytes, "")
+	if err != nil {
+		return nil, err
+	}
+	if !publicKeysEqual(certificates[0].PublicKey, privateKey.Public()) {
+		return nil, errors.New("private key does not match certificate")
+	}
+
+	// Hash input.
+	algorithm, hashFunc, err := signatureAlgorithm(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	digest, err := signatureDigest(hashFunc, src)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign digest.
+	var signerOpts baseCrypto.SignerOpts = hashFunc
+	switch algorithm {
+	case SignatureAlgorithmEd25519:
+		signerOpts = &ed25519.Options{Hash: hashFunc}
+	case SignatureAlgorithmRSAPSS:
+		signerOpts = &rsa.PSSOptions{Hash: hashFunc, SaltLength: rsa.PSSSaltLengthEqualsHash}
+	}
+	signature, err := privateKey.Sign(rand.Reader, digest, signerOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(pem.EncodeToMemory(&pem.Block{
+		Type:    signaturePEMType,
+		Headers: map[string]string{"Algorithm": algorithm},
+		Bytes:   signature,
+	}), encodeCertificatesPEM(certificates)...), nil
+}
+
+// Verify checks a detached signature created by Sign over the data read from src.
+// The signer's certificate must chain to roots, be valid for code signing and be valid at checkTime, or the current time if checkTime is zero. The signer's certificate is returned.
+func Verify(signatureBytes []byte, src io.Reader, roots *x509.CertPool, checkTime time.Time) (*x509.Certificate, error) {
+	return VerifyWithKeyUsages(signatureBytes, src, roots, checkTime, []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning})
+}
+
+// VerifyWithKeyUsages checks a detached signature as Verify does, but accepts signer certificates valid for any of keyUsages rather than only for code signing.
+func VerifyWithKeyUsages(signatureBytes []byte, src io.Reader, roots *x509.CertPool, checkTime time.Time, keyUsages []x509.ExtKeyUsage) (*x509.Certificate, error) {
+	if roots == nil {
+		return nil, errors.New("trusted roots not specified")
+	}
+
+	// Parse signature and certificates.
+	block, rest := pem.Decode(signatureBytes)
+	if block == nil || block.Type != signaturePEMType {
+		return nil, errors.New("no detached signature found")
+	}
+	certificates, err := parseCertificatesPEM(rest)
+	if err != nil {
+		return nil, err
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("signature does not include a certificate")
+	}
+	leaf := certificates[0]
+	algorithm, hashFunc, err := signatureAlgorithm(leaf.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if block.Headers["Algorithm"] != algorithm {
+		return nil, errors.New("signature algorithm does not match certificate: " + block.Headers["Algorithm"])
+	}
+
+	// Verify certificate chain.
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return nil, errors.New("certificate not valid for digital signatures")
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	if checkTime.IsZero() {
+		checkTime = time.Now()
+	}
+	if _, err = leaf.Verify(x509.VerifyOptions{
+		CurrentTime:   checkTime,
+		Intermediates: intermediates,
+		KeyUsages:     keyUsages,
+		Roots:         roots,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Verify signature.
+	digest, err := signatureDigest(hashFunc, src)
+	if err != nil {
+		return nil, err
+	}
+	switch publicKey := leaf.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(publicKey, digest, block.Bytes) {
+			return nil, errors.New("signature invalid")
+		}
+	case ed25519.PublicKey:
+		if err = ed25519.VerifyWithOptions(publicKey, digest, block.Bytes, &ed25519.Options{Hash: hashFunc}); err != nil {
+			return nil, errors.New("signature invalid")
+		}
+	case *rsa.PublicKey:
+		if err = rsa.VerifyPSS(publicKey, hashFunc, digest, block.Bytes, &rsa.PSSOptions{Hash: hashFunc, SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return nil, errors.New("signature invalid")
+		}
+	}
+
+	return leaf, nil
+}
+
+// signatureAlgorithm returns the detached signature algorithm and digest for a public key.
+func signatureAlgorithm(publicKey interface{}) (string, baseCrypto.Hash, error) {
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return SignatureAlgorithmECDSA, baseCrypto.SHA256, nil
+	case ed25519.PublicKey:
+		return SignatureAlgorithmEd25519, baseCrypto.SHA512, nil
+	case *rsa.PublicKey:
+		return SignatureAlgorithmRSAPSS, baseCrypto.SHA256, nil
+	default:
+		return "", 0, errors.New("unsupported signing key type")
+	}
+}
+
+// signatureDigest hashes the data read from src.
+func signatureDigest(hashFunc baseCrypto.Hash, src io.Reader) ([]byte, error) {
+	var hasher hash.Hash
+	if hashFunc == baseCrypto.SHA512 {
+		hasher = sha512.New()
+	} else {
+		hasher = sha256.New()
+	}
+	if _, err := io.Copy(hasher, src); err != nil {
+		return nil, err
+	}
+
+	return hasher.Sum(nil), nil
+}
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSign tests Sign(), Verify() and VerifyWithKeyUsages().
+func TestSign(t *testing.T) {
+	data := bytes.Repeat([]byte("release artifact "), 10000)
+
+	// Test self-signed certificates of each key type.
+	for _, ecdsaCurve := range []string{"rsa", "p256", "p384", "ed25519"} {
+		crtBytes, keyBytes, err := GenerateX509Certificate("signer.test", "Test", time.Now().Add(-time.Minute), time.Hour, false, 2048, ecdsaCurve)
+		assert.NoError(t, err)
+		signatureBytes, err := Sign(crtBytes, keyBytes, bytes.NewReader(data))
+		assert.NoError(t, err)
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM(crtBytes)
+		serverAuth := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		signer, err := VerifyWithKeyUsages(signatureBytes, bytes.NewReader(data), roots, time.Time{}, serverAuth)
+		assert.NoError(t, err, ecdsaCurve)
+		assert.Equal(t, []string{"signer.test"}, signer.DNSNames)
+
+		// Test a certificate not valid for code signing.
+		_, err = Verify(signatureBytes, bytes.NewReader(data), roots, time.Time{})
+		assert.Error(t, err, "Not a code signing certificate.")
+
+		// Test modified data.
+		_, err = VerifyWithKeyUsages(signatureBytes, strings.NewReader("modified"), roots, time.Time{}, serverAuth)
+		assert.Error(t, err, "Modified data.")
+
+		// Test expired certificate.
+		_, err = VerifyWithKeyUsages(signatureBytes, bytes.NewReader(data), roots, time.Now().Add(2*time.Hour), serverAuth)
+		assert.Error(t, err, "Expired certificate.")
+	}
+
+	// Test a chain through an intermediate.
+	ca, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Test Root"}})
+	assert.NoError(t, err)
+	intermediate, err := ca.NewIntermediate(CertificateOptions{Subject: pkix.Name{CommonName: "Test Intermediate"}})
+	assert.NoError(t, err)
+	crtBytes, keyBytes, err := intermediate.Issue(CertificateOptions{Subject: pkix.Name{CommonName: "Release Signing"}, DNSNames: []string{"release.test"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}})
+	assert.NoError(t, err)
+	signatureBytes, err := Sign(crtBytes, keyBytes, bytes.NewReader(data))
+	assert.NoError(t, err)
+	signer, err := Verify(signatureBytes, bytes.NewReader(data), ca.CertPool(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "Release Signing", signer.Subject.CommonName)
+	block, _ := pem.Decode(signatureBytes)
+	assert.Equal(t, SignatureAlgorithmECDSA, block.Headers["Algorithm"])
+
+	// Test an untrusted root.
+	otherCA, err := NewCA(CertificateOptions{Subject: pkix.Name{CommonName: "Other Root"}})
+	assert.NoError(t, err)
+	_, err = Verify(signatureBytes, bytes.NewReader(data), otherCA.CertPool(), time.Now())
+	assert.Error(t, err, "Untrusted root.")
+	_, err = Verify(signatureBytes, bytes.NewReader(data), nil, time.Now())
+	assert.Error(t, err, "No roots.")
+
+	// Test a mismatched key.
+	_, otherKeyBytes, err := intermediate.Issue(CertificateOptions{DNSNames: []string{"other.test"}})
+	assert.NoError(t, err)
+	_, err = Sign(crtBytes, otherKeyBytes, bytes.NewReader(data))
+	assert.Error(t, err, "Mismatched key.")
+
+	// Test a missing signature.
+	_, err = Verify(crtBytes, bytes.NewReader(data), ca.CertPool(), time.Now())
+	assert.Error(t, err, "No signature block.")
+}
@@ -0,0 +1,527 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Token signing algorithms.
+const (
+	TokenAlgorithmEdDSA = "EdDSA"
+	TokenAlgorithmHS256 = "HS256"
+)
+
+// Token formats.
+const (
+	TokenFormatCompact TokenFormat = iota // "v1.<algorithm>.<payload>.<signature>", or "v1.enc.<envelope>" when encrypted.
+	TokenFormatJWT                        // JWS compact serialization, nested in a JWE ("dir" key management with AES-GCM) when encrypted.
+)
+
+const (
+	// compactTokenVersion prefixes tokens in the compact format.
+	compactTokenVersion = "v1"
+
+	// minimumHMACKeyLength is the shortest HMAC key accepted.
+	minimumHMACKeyLength = 32
+)
+
+// Token validation errors, which may be wrapped with additional detail.
+var (
+	ErrTokenAlgorithm   = errors.New("token algorithm not permitted")
+	ErrTokenAudience    = errors.New("token audience not permitted")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenMalformed   = errors.New("token malformed")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrTokenSignature   = errors.New("token signature invalid")
+)
+
+var (
+	// registeredClaims are the claim names set from the standard fields of Claims, which custom claims may not use.
+	registeredClaims = map[string]bool{"aud": true, "exp": true, "iat": true, "iss": true, "jti": true, "nbf": true, "sub": true}
+)
+
+// TokenFormat selects how tokens are serialized.
+type TokenFormat int
+
+// Claims defines the claims carried by a token. Standard claims use their registered JWT names.
+type Claims struct {
+	Audience  []string               // Intended recipients ("aud").
+	Custom    map[string]interface{} // Additional claims, serialized alongside the standard claims; registered names are rejected.
+	ExpiresAt time.Time              // Expiry ("exp"); not checked if zero.
+	ID        string                 // Unique identifier ("jti").
+	IssuedAt  time.Time              // Issue time ("iat").
+	Issuer    string                 // Issuer ("iss").
+	NotBefore time.Time              // Start of validity ("nbf"); not checked if zero.
+	Subject   string                 // Subject ("sub").
+}
+
+// TokenOptions defines the keys and validation rules for issuing and verifying tokens.
+type TokenOptions struct {
+	Algorithm     string             // TokenAlgorithmHS256 or TokenAlgorithmEdDSA; the only algorithm accepted when verifying.
+	Audience      string             // Audience that must be present when verifying; not checked if empty.
+	EncryptionKey string             // Hex-encoded AES key; when set, tokens are encrypted with AES-GCM and unencrypted tokens are rejected.
+	Format        TokenFormat        // Serialization format.
+	HMACKey       []byte             // Key for TokenAlgorithmHS256; at least 32 bytes.
+	KeyID         string             // Key identifier recorded in issued tokens.
+	Leeway        time.Duration      // Tolerance for clock skew when checking expiry and start of validity.
+	Now           func() time.Time   // Current time; defaults to time.Now.
+	PrivateKey    ed25519.PrivateKey // Signing key for TokenAlgorithmEdDSA.
+	PublicKey     ed25519.PublicKey  // Verification key for TokenAlgorithmEdDSA; derived from PrivateKey if unset.
+}
+
+// jwtHeader defines a JOSE header.
+type jwtHeader struct {
+	Algorithm   string   `json:"alg"`
+	ContentType string   `json:"cty,omitempty"`
+	Critical    []string `json:"crit,omitempty"`
+	Encryption  string   `json:"enc,omitempty"`
+	KeyID       string   `json:"kid,omitempty"`
+	Type        string   `json:"typ,omitempty"`
+}
+
+// IssueToken creates a signed, and optionally encrypted, token carrying claims.
+func IssueToken(claims Claims, options TokenOptions) (string, error) {
+	if err := options.validate(true); err != nil {
+		return "", err
+	}
+
+	payload, err := jsoniter.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	switch options.Format {
+	case TokenFormatCompact:
+		signingInput := compactTokenVersion + "." + strings.ToLower(options.Algorithm) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		signature, err := options.sign([]byte(signingInput))
+		if err != nil {
+			return "", err
+		}
+		token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+		if options.EncryptionKey == "" {
+			return token, nil
+		}
+
+		envelopePrefix := compactTokenVersion + ".enc."
+		envelope, err := EncryptAESEnvelope(options.EncryptionKey, options.KeyID, []byte(token), []byte(envelopePrefix))
+		if err != nil {
+			return "", err
+		}
+		return envelopePrefix + base64.RawURLEncoding.EncodeToString(envelope), nil
+	case TokenFormatJWT:
+		header, err := jsoniter.Marshal(jwtHeader{Algorithm: options.Algorithm, KeyID: options.KeyID, Type: "JWT"})
+		if err != nil {
+			return "", err
+		}
+		signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		signature, err := options.sign([]byte(signingInput))
+		if err != nil {
+			return "", err
+		}
+		token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+		if options.EncryptionKey == "" {
+			return token, nil
+		}
+
+		return encryptJWE(options, []byte(token))
+	default:
+		return "", errors.New("unrecognized token format")
+	}
+}
+
+// VerifyToken verifies a token issued with the same options and validates its claims.
+// Tokens declaring any algorithm other than options.Algorithm are rejected with ErrTokenAlgorithm.
+func VerifyToken(token string, options TokenOptions) (*Claims, error) {
+	if err := options.validate(false); err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	var err error
+	switch options.Format {
+	case TokenFormatCompact:
+		payload, err = verifyCompactToken(token, options)
+	case TokenFormatJWT:
+		payload, err = verifyJWT(token, options)
+	default:
+		return nil, errors.New("unrecognized token format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	if err = jsoniter.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTokenMalformed, err.Error())
+	}
+	if err = claims.validate(options); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// MarshalJSON encodes claims as a JSON object using registered JWT claim names.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	values := make(map[string]interface{}, len(c.Custom)+7)
+	for name, value := range c.Custom {
+		if registeredClaims[name] {
+			return nil, errors.New("custom claim uses registered name: " + name)
+		}
+		values[name] = value
+	}
+	switch len(c.Audience) {
+	case 0:
+	case 1:
+		values["aud"] = c.Audience[0]
+	default:
+		values["aud"] = c.Audience
+	}
+	setNumericDate(values, "exp", c.ExpiresAt)
+	setNumericDate(values, "iat", c.IssuedAt)
+	setNumericDate(values, "nbf", c.NotBefore)
+	setString(values, "iss", c.Issuer)
+	setString(values, "jti", c.ID)
+	setString(values, "sub", c.Subject)
+
+	return jsoniter.Marshal(values)
+}
+
+// UnmarshalJSON decodes claims from a JSON object using registered JWT claim names.
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	var values map[string]jsoniter.RawMessage
+	if err := jsoniter.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*c = Claims{}
+	for name, value := range values {
+		var err error
+		switch name {
+		case "aud":
+			var audience string
+			if err = jsoniter.Unmarshal(value, &audience); err == nil {
+				c.Audience = []string{audience}
+			} else {
+				err = jsoniter.Unmarshal(value, &c.Audience)
+			}
+		case "exp":
+			c.ExpiresAt, err = parseNumericDate(value)
+		case "iat":
+			c.IssuedAt, err = parseNumericDate(value)
+		case "iss":
+			err = jsoniter.Unmarshal(value, &c.Issuer)
+		case "jti":
+			err = jsoniter.Unmarshal(value, &c.ID)
+		case "nbf":
+			c.NotBefore, err = parseNumericDate(value)
+		case "sub":
+			err = jsoniter.Unmarshal(value, &c.Subject)
+		default:
+			if c.Custom == nil {
+				c.Custom = make(map[string]interface{})
+			}
+			var customValue interface{}
+			err = jsoniter.Unmarshal(value, &customValue)
+			c.Custom[name] = customValue
+		}
+		if err != nil {
+			return errors.New("invalid claim " + name + ": " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validate checks the claims' validity period and audience.
+func (c *Claims) validate(options TokenOptions) error {
+	now := time.Now()
+	if options.Now != nil {
+		now = options.Now()
+	}
+
+	if !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt.Add(options.Leeway)) {
+		return ErrTokenExpired
+	}
+	if !c.NotBefore.IsZero() && now.Add(options.Leeway).Before(c.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+	if options.Audience != "" {
+		for _, audience := range c.Audience {
+			if audience == options.Audience {
+				return nil
+			}
+		}
+		return ErrTokenAudience
+	}
+
+	return nil
+}
+
+// sign signs a token's signing input.
+func (options TokenOptions) sign(signingInput []byte) ([]byte, error) {
+	switch options.Algorithm {
+	case TokenAlgorithmEdDSA:
+		return ed25519.Sign(options.PrivateKey, signingInput), nil
+	default:
+		mac := hmac.New(sha256.New, options.HMACKey)
+		mac.Write(signingInput) // nolint
+		return mac.Sum(nil), nil
+	}
+}
+
+// validate checks that the options contain the keys required to issue or verify tokens.
+func (options TokenOptions) validate(issuing bool) error {
+	switch options.Algorithm {
+	case TokenAlgorithmEdDSA:
+		if issuing && len(options.PrivateKey) != ed25519.PrivateKeySize {
+			return errors.New("private key required for EdDSA")
+		}
+		if !issuing && len(options.PublicKey) != 0 && len(options.PublicKey) != ed25519.PublicKeySize {
+			return errors.New("public key for EdDSA must be 32 bytes")
+		}
+		if !issuing && len(options.PublicKey) == 0 && len(options.PrivateKey) != ed25519.PrivateKeySize {
+			return errors.New("public key required for EdDSA")
+		}
+	case TokenAlgorithmHS256:
+		if len(options.HMACKey) < minimumHMACKeyLength {
+			return errors.New("HMAC key must be at least 32 bytes")
+		}
+	default:
+		return errors.New("unsupported token algorithm: " + options.Algorithm)
+	}
+
+	return nil
+}
+
+// verify checks a signature over a token's signing input.
+func (options TokenOptions) verify(signingInput []byte, signature []byte) error {
+	switch options.Algorithm {
+	case TokenAlgorithmEdDSA:
+		publicKey := options.PublicKey
+		if len(publicKey) == 0 {
+			publicKey = options.PrivateKey.Public().(ed25519.PublicKey)
+		}
+		if !ed25519.Verify(publicKey, signingInput, signature) {
+			return ErrTokenSignature
+		}
+	default:
+		expectedSignature, _ := options.sign(signingInput)
+		if !hmac.Equal(expectedSignature, signature) {
+			return ErrTokenSignature
+		}
+	}
+
+	return nil
+}
+
+// encryptJWE encrypts a nested JWT as a JWE compact serialization using direct key agreement and AES-GCM.
+func encryptJWE(options TokenOptions, plaintext []byte) (string, error) {
+	aesGCM, err := newAESGCM(options.EncryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := jsoniter.Marshal(jwtHeader{Algorithm: "dir", ContentType: "JWT", Encryption: jweEncryption(options.EncryptionKey), KeyID: options.KeyID})
+	if err != nil {
+		return "", err
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+	iv := make([]byte, aesGCM.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+	sealed := aesGCM.Seal(nil, iv, plaintext, []byte(encodedHeader))
+	ciphertext, tag := sealed[:len(sealed)-aesGCM.Overhead()], sealed[len(sealed)-aesGCM.Overhead():]
+
+	return encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(iv) + "." + base64.RawURLEncoding.EncodeToString(ciphertext) + "." + base64.RawURLEncoding.EncodeToString(tag), nil
+}
+
+// decryptJWE decrypts a JWE compact serialization produced by encryptJWE.
+func decryptJWE(options TokenOptions, token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, ErrTokenMalformed
+	}
+
+	var header jwtHeader
+	if err := decodeTokenSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != "dir" || header.Encryption != jweEncryption(options.EncryptionKey) || parts[1] != "" {
+		return nil, ErrTokenAlgorithm
+	}
+	if len(header.Critical) > 0 {
+		return nil, fmt.Errorf("%w: unsupported critical header", ErrTokenMalformed)
+	}
+
+	aesGCM, err := newAESGCM(options.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	iv, ivErr := base64.RawURLEncoding.DecodeString(parts[2])
+	ciphertext, ciphertextErr := base64.RawURLEncoding.DecodeString(parts[3])
+	tag, tagErr := base64.RawURLEncoding.DecodeString(parts[4])
+	if ivErr != nil || ciphertextErr != nil || tagErr != nil || len(iv) != aesGCM.NonceSize() || len(tag) != aesGCM.Overhead() {
+		return nil, ErrTokenMalformed
+	}
+	plaintext, err := aesGCM.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, ErrTokenSignature
+	}
+
+	return plaintext, nil
+}
+
+// decodeTokenSegment decodes a base64url-encoded JSON token segment.
+func decodeTokenSegment(segment string, output interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrTokenMalformed, err.Error())
+	}
+	if err = jsoniter.Unmarshal(decoded, output); err != nil {
+		return fmt.Errorf("%w: %s", ErrTokenMalformed, err.Error())
+	}
+
+	return nil
+}
+
+// jweEncryption returns the JWE content encryption algorithm for a hex-encoded AES key.
+func jweEncryption(key string) string {
+	return fmt.Sprintf("A%dGCM", len(key)*4)
+}
+
+// parseNumericDate parses a JWT NumericDate (seconds since the epoch).
+func parseNumericDate(value []byte) (time.Time, error) {
+	var seconds float64
+	if err := jsoniter.Unmarshal(value, &seconds); err != nil {
+		return time.Time{}, err
+	}
+	if math.IsNaN(seconds) || math.Abs(seconds) > math.MaxInt64/float64(time.Second) {
+		return time.Time{}, errors.New("numeric date out of range")
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+}
+
+// setNumericDate sets a JWT NumericDate claim if the time is not zero.
+func setNumericDate(values map[string]interface{}, name string, value time.Time) {
+	if !value.IsZero() {
+		values[name] = value.Unix()
+	}
+}
+
+// setString sets a string claim if it is not empty.
+func setString(values map[string]interface{}, name string, value string) {
+	if value != "" {
+		values[name] = value
+	}
+}
+
+// verifyCompactToken verifies a token in the compact format, returning its payload.
+func verifyCompactToken(token string, options TokenOptions) ([]byte, error) {
+	// Decrypt.
+	envelopePrefix := compactTokenVersion + ".enc."
+	if strings.HasPrefix(token, envelopePrefix) {
+		if options.EncryptionKey == "" {
+			return nil, ErrTokenAlgorithm
+		}
+		envelope, err := base64.RawURLEncoding.DecodeString(token[len(envelopePrefix):])
+		if err != nil {
+			return nil, ErrTokenMalformed
+		}
+		plaintext, _, err := DecryptAESEnvelope(options.EncryptionKey, envelope, []byte(envelopePrefix))
+		if err != nil {
+			return nil, ErrTokenSignature
+		}
+		token = string(plaintext)
+	} else if options.EncryptionKey != "" {
+		return nil, ErrTokenAlgorithm
+	}
+
+	// Verify signature.
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] != compactTokenVersion {
+		return nil, ErrTokenMalformed
+	}
+	if parts[1] != strings.ToLower(options.Algorithm) {
+		return nil, ErrTokenAlgorithm
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err = options.verify([]byte(token[:strings.LastIndex(token, ".")]), signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	return payload, nil
+}
+
+// verifyJWT verifies a JWS, or a JWE containing a JWS, returning its payload.
+func verifyJWT(token string, options TokenOptions) ([]byte, error) {
+	// Decrypt.
+	switch strings.Count(token, ".") {
+	case 4:
+		if options.EncryptionKey == "" {
+			return nil, ErrTokenAlgorithm
+		}
+		plaintext, err := decryptJWE(options, token)
+		if err != nil {
+			return nil, err
+		}
+		token = string(plaintext)
+	case 2:
+		if options.EncryptionKey != "" {
+			return nil, ErrTokenAlgorithm
+		}
+	default:
+		return nil, ErrTokenMalformed
+	}
+
+	// Verify signature.
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	var header jwtHeader
+	if err := decodeTokenSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != options.Algorithm {
+		return nil, ErrTokenAlgorithm
+	}
+	if len(header.Critical) > 0 {
+		return nil, fmt.Errorf("%w: unsupported critical header", ErrTokenMalformed)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err = options.verify([]byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	return payload, nil
+}
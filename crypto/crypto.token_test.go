@@ -0,0 +1,129 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIssueToken tests IssueToken() and VerifyToken().
+func TestIssueToken(t *testing.T) {
+	hmacKey := make([]byte, 32)
+	_, err := rand.Read(hmacKey)
+	assert.NoError(t, err)
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	encryptionKey, err := GenerateAESKey(32)
+	assert.NoError(t, err)
+	now := time.Unix(1700000000, 0).UTC()
+	claims := Claims{
+		Audience:  []string{"api"},
+		Custom:    map[string]interface{}{"role": "admin"},
+		ExpiresAt: now.Add(time.Hour),
+		IssuedAt:  now,
+		Issuer:    "issuer",
+		NotBefore: now,
+		Subject:   "user-1",
+	}
+
+	// Test every combination of format, algorithm and encryption.
+	for _, format := range []TokenFormat{TokenFormatCompact, TokenFormatJWT} {
+		for _, options := range []TokenOptions{
+			{Algorithm: TokenAlgorithmHS256, HMACKey: hmacKey},
+			{Algorithm: TokenAlgorithmEdDSA, PrivateKey: privateKey},
+			{Algorithm: TokenAlgorithmHS256, EncryptionKey: encryptionKey, HMACKey: hmacKey, KeyID: "k1"},
+			{Algorithm: TokenAlgorithmEdDSA, EncryptionKey: encryptionKey, PrivateKey: privateKey},
+		} {
+			options.Audience = "api"
+			options.Format = format
+			options.Now = func() time.Time { return now.Add(time.Minute) }
+			token, err := IssueToken(claims, options)
+			assert.NoError(t, err)
+
+			verifyOptions := options
+			verifyOptions.PrivateKey = nil
+			verifyOptions.PublicKey = publicKey
+			verified, err := VerifyToken(token, verifyOptions)
+			assert.NoError(t, err)
+			if assert.NotNil(t, verified) {
+				assert.Equal(t, claims.Subject, verified.Subject)
+				assert.Equal(t, claims.ExpiresAt, verified.ExpiresAt)
+				assert.Equal(t, claims.Audience, verified.Audience)
+				assert.Equal(t, "admin", verified.Custom["role"])
+			}
+
+			// Test encryption mismatches.
+			swappedOptions := verifyOptions
+			if options.EncryptionKey == "" {
+				swappedOptions.EncryptionKey = encryptionKey
+			} else {
+				swappedOptions.EncryptionKey = ""
+			}
+			_, err = VerifyToken(token, swappedOptions)
+			assert.ErrorIs(t, err, ErrTokenAlgorithm)
+
+			// Test expiry, start of validity and audience.
+			expiredOptions := verifyOptions
+			expiredOptions.Now = func() time.Time { return now.Add(2 * time.Hour) }
+			_, err = VerifyToken(token, expiredOptions)
+			assert.ErrorIs(t, err, ErrTokenExpired)
+			expiredOptions.Leeway = 2 * time.Hour
+			_, err = VerifyToken(token, expiredOptions)
+			assert.NoError(t, err, "Within leeway.")
+			earlyOptions := verifyOptions
+			earlyOptions.Now = func() time.Time { return now.Add(-time.Hour) }
+			_, err = VerifyToken(token, earlyOptions)
+			assert.ErrorIs(t, err, ErrTokenNotYetValid)
+			audienceOptions := verifyOptions
+			audienceOptions.Audience = "other"
+			_, err = VerifyToken(token, audienceOptions)
+			assert.ErrorIs(t, err, ErrTokenAudience)
+		}
+	}
+
+	// Test algorithm confusion.
+	options := TokenOptions{Algorithm: TokenAlgorithmHS256, Format: TokenFormatJWT, HMACKey: hmacKey}
+	token, err := IssueToken(claims, options)
+	assert.NoError(t, err)
+	_, err = VerifyToken(token, TokenOptions{Algorithm: TokenAlgorithmEdDSA, Format: TokenFormatJWT, PublicKey: publicKey})
+	assert.ErrorIs(t, err, ErrTokenAlgorithm)
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	parts := strings.Split(token, ".")
+	_, err = VerifyToken(noneHeader+"."+parts[1]+".", options)
+	assert.ErrorIs(t, err, ErrTokenAlgorithm)
+	compactOptions := TokenOptions{Algorithm: TokenAlgorithmEdDSA, PrivateKey: privateKey}
+	compactToken, err := IssueToken(claims, compactOptions)
+	assert.NoError(t, err)
+	_, err = VerifyToken(compactToken, TokenOptions{Algorithm: TokenAlgorithmHS256, HMACKey: hmacKey})
+	assert.ErrorIs(t, err, ErrTokenAlgorithm)
+
+	// Test tampering.
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-2"}`))
+	_, err = VerifyToken(strings.Join(parts, "."), options)
+	assert.ErrorIs(t, err, ErrTokenSignature)
+	_, err = VerifyToken("v1.eddsa.e30.AAAA", compactOptions)
+	assert.ErrorIs(t, err, ErrTokenSignature)
+	_, err = VerifyToken("garbage", options)
+	assert.ErrorIs(t, err, ErrTokenMalformed)
+
+	// Test invalid keys.
+	_, err = IssueToken(claims, TokenOptions{Algorithm: TokenAlgorithmHS256, HMACKey: []byte("short")})
+	assert.Error(t, err, "Short HMAC key.")
+	_, err = IssueToken(claims, TokenOptions{Algorithm: "none"})
+	assert.Error(t, err, "Unsupported algorithm.")
+	_, err = VerifyToken(compactToken, TokenOptions{Algorithm: TokenAlgorithmEdDSA, PublicKey: publicKey[:5]})
+	assert.Error(t, err, "Short public key.")
+
+	// Test custom claims with registered names.
+	_, err = IssueToken(Claims{Custom: map[string]interface{}{"exp": 0}}, options)
+	assert.Error(t, err, "Registered claim name.")
+
+	// Test out of range dates.
+	assert.Error(t, (&Claims{}).UnmarshalJSON([]byte(`{"exp":1e300}`)), "Out of range date.")
+	assert.Error(t, (&Claims{}).UnmarshalJSON([]byte(`{"nbf":-1e19}`)), "Out of range date.")
+}
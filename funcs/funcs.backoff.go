@@ -0,0 +1,155 @@
+package funcs
+
+import (
+	"math"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+const (
+	// defaultBackoffInitial is the first delay when a backoff does not specify one.
+	defaultBackoffInitial = 100 * time.Millisecond
+
+	// defaultBackoffMax is the largest delay when a backoff does not specify one.
+	defaultBackoffMax = 30 * time.Second
+
+	// defaultBackoffMultiplier is the exponential growth factor when a backoff does not specify one.
+	defaultBackoffMultiplier = 2.0
+)
+
+// Backoff calculates the delay between retry attempts.
+type Backoff interface {
+	// Next returns the delay after the failed attempt numbered attempt (starting at zero), given the previous delay.
+	Next(attempt int, previous time.Duration) time.Duration
+}
+
+// ConstantBackoff waits the same interval between attempts.
+type ConstantBackoff struct {
+	Interval     time.Duration          // Delay between attempts; defaults to 100ms.
+	Jitter       float64                // Fraction of the delay by which it is randomly varied, from 0 to 1.
+	JitterSource timeUtils.JitterSource // Random source for jitter; defaults to time.DefaultJitterSource.
+}
+
+// DecorrelatedJitterBackoff waits a random delay between the base and three times the previous delay, as described at https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+type DecorrelatedJitterBackoff struct {
+	Base         time.Duration          // Smallest delay; defaults to 100ms.
+	JitterSource timeUtils.JitterSource // Random source for delays; defaults to time.DefaultJitterSource.
+	Max          time.Duration          // Largest delay; defaults to 30s.
+}
+
+// ExponentialBackoff multiplies the delay after each attempt.
+type ExponentialBackoff struct {
+	Initial      time.Duration          // First delay; defaults to 100ms.
+	Jitter       float64                // Fraction of the delay by which it is randomly varied, from 0 to 1.
+	JitterSource timeUtils.JitterSource // Random source for jitter; defaults to time.DefaultJitterSource.
+	Max          time.Duration          // Largest delay; defaults to 30s.
+	Multiplier   float64                // Growth factor; defaults to 2.
+}
+
+// FibonacciBackoff grows the delay along the Fibonacci sequence (1, 1, 2, 3, 5, ...) of the initial delay.
+type FibonacciBackoff struct {
+	Initial      time.Duration          // First delay; defaults to 100ms.
+	Jitter       float64                // Fraction of the delay by which it is randomly varied, from 0 to 1.
+	JitterSource timeUtils.JitterSource // Random source for jitter; defaults to time.DefaultJitterSource.
+	Max          time.Duration          // Largest delay; defaults to 30s.
+}
+
+// Next returns the constant delay.
+func (b ConstantBackoff) Next(attempt int, previous time.Duration) time.Duration {
+	interval := b.Interval
+	if interval <= 0 {
+		interval = defaultBackoffInitial
+	}
+
+	return jitter(b.JitterSource, interval, b.Jitter)
+}
+
+// Next returns a random delay between the base and three times the previous delay, or the base if there is no previous delay, capped at the maximum.
+func (b DecorrelatedJitterBackoff) Next(attempt int, previous time.Duration) time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = defaultBackoffInitial
+	}
+	maxDelay := b.Max
+	if maxDelay <= 0 {
+		maxDelay = defaultBackoffMax
+	}
+
+	if base >= maxDelay {
+		return maxDelay
+	}
+
+	// The first delay is drawn as if the previous one were the base.
+	previous = min(max(previous, base), maxDelay)
+	delay := base + time.Duration(jitterSourceOrDefault(b.JitterSource).Int63n(int64(3*previous-base)))
+
+	return min(delay, maxDelay)
+}
+
+// Next returns the initial delay multiplied by the multiplier once per attempt, capped at the maximum.
+func (b ExponentialBackoff) Next(attempt int, previous time.Duration) time.Duration {
+	initial := b.Initial
+	if initial <= 0 {
+		initial = defaultBackoffInitial
+	}
+	maxDelay := b.Max
+	if maxDelay <= 0 {
+		maxDelay = defaultBackoffMax
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultBackoffMultiplier
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt))
+	if delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+
+	return jitter(b.JitterSource, time.Duration(delay), b.Jitter)
+}
+
+// Next returns the initial delay multiplied by the Fibonacci number for the attempt, capped at the maximum.
+func (b FibonacciBackoff) Next(attempt int, previous time.Duration) time.Duration {
+	initial := b.Initial
+	if initial <= 0 {
+		initial = defaultBackoffInitial
+	}
+	maxDelay := b.Max
+	if maxDelay <= 0 {
+		maxDelay = defaultBackoffMax
+	}
+
+	delay := initial
+	previousDelay := time.Duration(0)
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay, previousDelay = delay+previousDelay, delay
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return jitter(b.JitterSource, delay, b.Jitter)
+}
+
+// jitter randomly varies a delay by up to fraction of its length in either direction.
+func jitter(jitterSource timeUtils.JitterSource, delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || delay <= 0 {
+		return delay
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+
+	return delay + time.Duration((jitterSourceOrDefault(jitterSource).Float64()*2-1)*fraction*float64(delay))
+}
+
+// jitterSourceOrDefault returns jitterSource, or the default source if it is nil.
+func jitterSourceOrDefault(jitterSource timeUtils.JitterSource) timeUtils.JitterSource {
+	if jitterSource == nil {
+		return timeUtils.DefaultJitterSource
+	}
+
+	return jitterSource
+}
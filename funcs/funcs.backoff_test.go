@@ -0,0 +1,72 @@
+package funcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConstantBackoff tests ConstantBackoff.
+func TestConstantBackoff(t *testing.T) {
+	backoff := ConstantBackoff{Interval: time.Second}
+	assert.Equal(t, time.Second, backoff.Next(0, 0))
+	assert.Equal(t, time.Second, backoff.Next(10, time.Second))
+
+	// Test jitter.
+	backoff.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := backoff.Next(i, 0)
+		assert.True(t, delay >= 500*time.Millisecond && delay <= 1500*time.Millisecond, "Jitter out of range.")
+	}
+
+	// Test defaults.
+	assert.Equal(t, defaultBackoffInitial, ConstantBackoff{}.Next(0, 0))
+}
+
+// TestDecorrelatedJitterBackoff tests DecorrelatedJitterBackoff.
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	backoff := DecorrelatedJitterBackoff{Base: 10 * time.Millisecond, Max: time.Second}
+	var delay time.Duration
+	for i := 0; i < 100; i++ {
+		previous := delay
+		delay = backoff.Next(i, previous)
+		assert.True(t, delay >= 10*time.Millisecond, "Delay below base.")
+		assert.True(t, delay <= time.Second, "Delay above maximum.")
+		if previous > 0 {
+			assert.True(t, delay <= 3*previous, "Delay above three times previous.")
+		}
+	}
+
+	// Test the first delay.
+	for i := 0; i < 100; i++ {
+		delay = backoff.Next(0, 0)
+		assert.True(t, delay >= 10*time.Millisecond && delay <= 30*time.Millisecond, "First delay outside base to three times base.")
+	}
+
+	// Test a base above the maximum.
+	assert.Equal(t, time.Second, DecorrelatedJitterBackoff{Base: 5 * time.Second, Max: time.Second}.Next(0, 0))
+}
+
+// TestExponentialBackoff tests ExponentialBackoff.
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 3}
+	assert.Equal(t, 100*time.Millisecond, backoff.Next(0, 0))
+	assert.Equal(t, 300*time.Millisecond, backoff.Next(1, 0))
+	assert.Equal(t, 900*time.Millisecond, backoff.Next(2, 0))
+	assert.Equal(t, time.Second, backoff.Next(3, 0), "Capped at maximum.")
+	assert.Equal(t, time.Second, backoff.Next(1000, 0), "Capped at maximum.")
+
+	// Test defaults.
+	assert.Equal(t, 2*defaultBackoffInitial, ExponentialBackoff{}.Next(1, 0))
+}
+
+// TestFibonacciBackoff tests FibonacciBackoff.
+func TestFibonacciBackoff(t *testing.T) {
+	backoff := FibonacciBackoff{Initial: time.Second, Max: 10 * time.Second}
+	expected := []time.Duration{1, 1, 2, 3, 5, 8, 10, 10}
+	for attempt, multiple := range expected {
+		assert.Equal(t, multiple*time.Second, backoff.Next(attempt, 0))
+	}
+	assert.Equal(t, 10*time.Second, backoff.Next(1000, 0), "Capped at maximum.")
+}
@@ -0,0 +1,86 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrBulkheadFull is returned when a bulkhead's executions and queue are both full.
+	ErrBulkheadFull = errors.New("bulkhead full")
+)
+
+// Bulkhead limits concurrent executions, queueing a bounded number of callers and rejecting the rest.
+type Bulkhead struct {
+	admitted chan struct{}
+	running  chan struct{}
+}
+
+// NewBulkhead creates a bulkhead running up to maxConcurrent calls at once, with up to maxQueue more waiting.
+func NewBulkhead(maxConcurrent int, maxQueue int) *Bulkhead {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+
+	return &Bulkhead{
+		admitted: make(chan struct{}, maxConcurrent+maxQueue),
+		running:  make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Acquire waits for an execution slot, returning a function that releases it.
+// It returns ErrBulkheadFull immediately if the queue is full, or the context's error if it is done while queued.
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.admitted <- struct{}{}:
+	default:
+		return nil, ErrBulkheadFull
+	}
+
+	select {
+	case b.running <- struct{}{}:
+	case <-ctx.Done():
+		<-b.admitted
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-b.running
+			<-b.admitted
+		})
+	}, nil
+}
+
+// Execute calls method once an execution slot is available.
+func (b *Bulkhead) Execute(ctx context.Context, method func(ctx context.Context) error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return method(ctx)
+}
+
+// Queued returns the number of callers waiting for a slot.
+func (b *Bulkhead) Queued() int {
+	return len(b.admitted) - len(b.running)
+}
+
+// Running returns the number of executions in progress.
+func (b *Bulkhead) Running() int {
+	return len(b.running)
+}
+
+// Wrap returns a function that calls method through the bulkhead.
+func (b *Bulkhead) Wrap(method func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return b.Execute(ctx, method)
+	}
+}
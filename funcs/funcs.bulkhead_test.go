@@ -0,0 +1,67 @@
+package funcs
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBulkhead tests Bulkhead.
+func TestBulkhead(t *testing.T) {
+	bulkhead := NewBulkhead(2, 1)
+	block := make(chan struct{})
+	var running int32
+	var maxRunning int32
+	method := bulkhead.Wrap(func(context.Context) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			previous := atomic.LoadInt32(&maxRunning)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+				break
+			}
+		}
+		<-block
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+
+	// Fill the executions and queue.
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, method(context.Background()))
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		return bulkhead.Running() == 2 && bulkhead.Queued() == 1
+	}, time.Second, time.Millisecond)
+
+	// Test rejection when full.
+	assert.ErrorIs(t, method(context.Background()), ErrBulkheadFull)
+
+	// Test release.
+	close(block)
+	wg.Wait()
+	assert.Equal(t, int32(2), maxRunning, "Concurrency capped.")
+	assert.Equal(t, 0, bulkhead.Running())
+	assert.Equal(t, 0, bulkhead.Queued())
+
+	// Test cancellation while queued.
+	bulkhead = NewBulkhead(1, 1)
+	release, err := bulkhead.Acquire(context.Background())
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = bulkhead.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, bulkhead.Queued(), "Queue slot released.")
+	release()
+	release()
+	assert.Equal(t, 0, bulkhead.Running(), "Release is idempotent.")
+}
@@ -0,0 +1,337 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+// Circuit breaker states.
+const (
+	CircuitClosed   CircuitState = iota // Calls are permitted and outcomes recorded.
+	CircuitOpen                         // Calls fail immediately with ErrCircuitOpen.
+	CircuitHalfOpen                     // A limited number of probe calls are permitted to test recovery.
+)
+
+const (
+	// circuitBuckets is the number of buckets in a circuit breaker's rolling window.
+	circuitBuckets = 10
+
+	// defaultCircuitConsecutiveFailures is the consecutive failure threshold set by NewCircuitBreaker.
+	defaultCircuitConsecutiveFailures = 5
+
+	// defaultCircuitFailureRate is the failure rate threshold set by NewCircuitBreaker.
+	defaultCircuitFailureRate = 0.5
+
+	// defaultCircuitMinimumCalls is the number of calls in the window required before the failure rate is evaluated.
+	defaultCircuitMinimumCalls = 10
+
+	// defaultCircuitOpenTimeout is how long a breaker stays open when no timeout is specified.
+	defaultCircuitOpenTimeout = 30 * time.Second
+
+	// defaultCircuitWindow is the rolling window length when none is specified.
+	defaultCircuitWindow = time.Minute
+)
+
+var (
+	// ErrCircuitOpen is returned when a call is rejected by an open circuit breaker. Retry and RetryFunc stop immediately on this error.
+	ErrCircuitOpen = errors.New("circuit breaker open")
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// CircuitBreaker stops calls to a failing dependency, opening after too many failures and probing for recovery after a timeout.
+type CircuitBreaker struct {
+	Clock               timeUtils.Clock             // Clock used for the open timeout and rolling window; defaults to time.RealClock.
+	ConsecutiveFailures int                         // Consecutive failures that open the breaker; disabled if zero.
+	FailureRate         float64                     // Fraction of calls within the window that must fail to open the breaker; disabled if zero.
+	HalfOpenProbes      int                         // Concurrent probe calls permitted while half-open, all of which must succeed to close the breaker; defaults to 1.
+	IsFailure           func(err error) bool        // Reports whether an error counts as a failure; defaults to any error except context cancellation. Cancellations that are not failures are ignored.
+	MinimumCalls        int                         // Calls within the window required before the failure rate is evaluated; defaults to 10.
+	OnStateChange       func(from, to CircuitState) // Called after each state change.
+	OpenTimeout         time.Duration               // How long the breaker stays open before probing; defaults to 30s.
+	Window              time.Duration               // Length of the rolling window for the failure rate; defaults to one minute.
+
+	buckets           [circuitBuckets]circuitBucket
+	consecutive       int
+	generation        uint64
+	lock              sync.Mutex
+	openedAt          time.Time
+	probeSuccesses    int
+	probesOutstanding int
+	state             CircuitState
+}
+
+// circuitBucket defines call outcomes in part of a rolling window.
+type circuitBucket struct {
+	failures  int
+	start     time.Time
+	successes int
+}
+
+// NewCircuitBreaker creates a circuit breaker that opens after five consecutive failures, or when half of at least ten calls in a minute fail.
+func NewCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{
+		ConsecutiveFailures: defaultCircuitConsecutiveFailures,
+		FailureRate:         defaultCircuitFailureRate,
+		HalfOpenProbes:      1,
+		MinimumCalls:        defaultCircuitMinimumCalls,
+		OpenTimeout:         defaultCircuitOpenTimeout,
+		Window:              defaultCircuitWindow,
+	}
+}
+
+// Allow reserves a call, returning a function that must be called with the call's outcome.
+// It returns ErrCircuitOpen if the breaker is open, or half-open with all probes in progress.
+func (cb *CircuitBreaker) Allow() (func(err error), error) {
+	cb.lock.Lock()
+	from := cb.state
+	now := cb.now()
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.openTimeout() {
+		cb.setState(CircuitHalfOpen, now)
+	}
+	switch cb.state {
+	case CircuitOpen:
+		cb.lock.Unlock()
+		return nil, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.probesOutstanding >= cb.halfOpenProbes() {
+			cb.lock.Unlock()
+			cb.notify(from, CircuitHalfOpen)
+			return nil, ErrCircuitOpen
+		}
+		cb.probesOutstanding++
+	}
+	generation := cb.generation
+	to := cb.state
+	cb.lock.Unlock()
+	cb.notify(from, to)
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			cb.record(generation, err)
+		})
+	}, nil
+}
+
+// Execute calls method if the breaker permits it, recording the outcome. A panic in method is recorded as a failure and then propagated.
+func (cb *CircuitBreaker) Execute(ctx context.Context, method func(ctx context.Context) error) (err error) {
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			done(fmt.Errorf("panic: %v", recovered))
+			panic(recovered)
+		}
+		done(err)
+	}()
+
+	return method(ctx)
+}
+
+// Reset closes the breaker and clears its counts.
+func (cb *CircuitBreaker) Reset() {
+	cb.lock.Lock()
+	from := cb.state
+	cb.setState(CircuitClosed, cb.now())
+	cb.lock.Unlock()
+	cb.notify(from, CircuitClosed)
+}
+
+// State returns the breaker's current state.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.openTimeout() {
+		return CircuitHalfOpen
+	}
+
+	return cb.state
+}
+
+// String returns the name of a circuit state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Wrap returns a function that calls method through the breaker.
+func (cb *CircuitBreaker) Wrap(method func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return cb.Execute(ctx, method)
+	}
+}
+
+// halfOpenProbes returns the number of probes permitted while half-open.
+func (cb *CircuitBreaker) halfOpenProbes() int {
+	if cb.HalfOpenProbes < 1 {
+		return 1
+	}
+
+	return cb.HalfOpenProbes
+}
+
+// isFailure reports whether an error counts as a failure.
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	if cb.IsFailure != nil {
+		return cb.IsFailure(err)
+	}
+
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// notify calls the state change callback if the state changed.
+func (cb *CircuitBreaker) notify(from CircuitState, to CircuitState) {
+	if from != to && cb.OnStateChange != nil {
+		cb.OnStateChange(from, to)
+	}
+}
+
+// now returns the current time from the breaker's clock.
+func (cb *CircuitBreaker) now() time.Time {
+	if cb.Clock == nil {
+		return time.Now()
+	}
+
+	return cb.Clock.Now()
+}
+
+// openTimeout returns how long the breaker stays open.
+func (cb *CircuitBreaker) openTimeout() time.Duration {
+	if cb.OpenTimeout <= 0 {
+		return defaultCircuitOpenTimeout
+	}
+
+	return cb.OpenTimeout
+}
+
+// record records the outcome of a call permitted in a generation.
+func (cb *CircuitBreaker) record(generation uint64, err error) {
+	failed := cb.isFailure(err)
+	cancelled := !failed && errors.Is(err, context.Canceled)
+
+	cb.lock.Lock()
+	from := cb.state
+	now := cb.now()
+	if generation != cb.generation {
+		// The call started before the last state change.
+		cb.lock.Unlock()
+		return
+	}
+
+	switch cb.state {
+	case CircuitClosed:
+		if cancelled {
+			// Cancellations say nothing about the dependency's health.
+			break
+		}
+		cb.recordWindow(now, failed)
+		if failed {
+			cb.consecutive++
+		} else {
+			cb.consecutive = 0
+		}
+		if cb.shouldOpen(now) {
+			cb.setState(CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		cb.probesOutstanding--
+		if failed {
+			cb.setState(CircuitOpen, now)
+		} else if !cancelled {
+			cb.probeSuccesses++
+			if cb.probeSuccesses >= cb.halfOpenProbes() {
+				cb.setState(CircuitClosed, now)
+			}
+		}
+	}
+	to := cb.state
+	cb.lock.Unlock()
+	cb.notify(from, to)
+}
+
+// recordWindow records a call outcome in the rolling window.
+func (cb *CircuitBreaker) recordWindow(now time.Time, failed bool) {
+	width := cb.window() / circuitBuckets
+	start := now.Truncate(width)
+	bucket := &cb.buckets[(start.UnixNano()/int64(width))%circuitBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+	if failed {
+		bucket.failures++
+	} else {
+		bucket.successes++
+	}
+}
+
+// setState changes state, resetting counts.
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	if state == cb.state && state != CircuitClosed {
+		return
+	}
+
+	cb.state = state
+	cb.generation++
+	cb.consecutive = 0
+	cb.probeSuccesses = 0
+	cb.probesOutstanding = 0
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+	if state == CircuitClosed {
+		cb.buckets = [circuitBuckets]circuitBucket{}
+	}
+}
+
+// shouldOpen reports whether a closed breaker's thresholds have been exceeded.
+func (cb *CircuitBreaker) shouldOpen(now time.Time) bool {
+	if cb.ConsecutiveFailures > 0 && cb.consecutive >= cb.ConsecutiveFailures {
+		return true
+	}
+	if cb.FailureRate <= 0 {
+		return false
+	}
+
+	// Sum the rolling window.
+	failures, calls := 0, 0
+	window := cb.window()
+	for _, bucket := range cb.buckets {
+		if now.Sub(bucket.start) < window {
+			failures += bucket.failures
+			calls += bucket.failures + bucket.successes
+		}
+	}
+	minimumCalls := cb.MinimumCalls
+	if minimumCalls < 1 {
+		minimumCalls = defaultCircuitMinimumCalls
+	}
+
+	return calls >= minimumCalls && float64(failures)/float64(calls) >= cb.FailureRate
+}
+
+// window returns the rolling window length.
+func (cb *CircuitBreaker) window() time.Duration {
+	if cb.Window < circuitBuckets {
+		return defaultCircuitWindow
+	}
+
+	return cb.Window
+}
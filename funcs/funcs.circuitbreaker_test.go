@@ -0,0 +1,172 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCircuitBreaker tests CircuitBreaker.
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	sampleErr := errors.New("sample error")
+	var lock sync.Mutex
+	transitions := []string{}
+	cb := NewCircuitBreaker()
+	cb.ConsecutiveFailures = 3
+	cb.OpenTimeout = 50 * time.Millisecond
+	cb.OnStateChange = func(from, to CircuitState) {
+		lock.Lock()
+		defer lock.Unlock()
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}
+
+	// Test opening after consecutive failures.
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, CircuitClosed, cb.State())
+		err := cb.Execute(ctx, func(context.Context) error {
+			return sampleErr
+		})
+		assert.ErrorIs(t, err, sampleErr)
+	}
+	assert.Equal(t, CircuitOpen, cb.State())
+	calls := 0
+	err := cb.Execute(ctx, func(context.Context) error {
+		calls++
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 0, calls, "Open breaker fails fast.")
+
+	// Test a failed probe reopening the breaker.
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	err = cb.Execute(ctx, func(context.Context) error {
+		return sampleErr
+	})
+	assert.ErrorIs(t, err, sampleErr)
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	// Test the half-open probe limit and a successful probe closing the breaker.
+	time.Sleep(60 * time.Millisecond)
+	done, err := cb.Allow()
+	assert.NoError(t, err)
+	_, err = cb.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen, "Probe limit reached.")
+	done(nil)
+	assert.Equal(t, CircuitClosed, cb.State())
+	lock.Lock()
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}, transitions)
+	lock.Unlock()
+
+	// Test cancellation not counting as a failure.
+	for i := 0; i < 5; i++ {
+		_ = cb.Execute(ctx, func(context.Context) error {
+			return context.Canceled
+		})
+	}
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	// Test panics counting as failures.
+	for i := 0; i < 3; i++ {
+		assert.PanicsWithValue(t, "sample panic", func() {
+			_ = cb.Execute(ctx, func(context.Context) error {
+				panic("sample panic")
+			})
+		})
+	}
+	assert.Equal(t, CircuitOpen, cb.State())
+}
+
+// TestCircuitBreakerFailureRate tests CircuitBreaker failure rates.
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	cb := &CircuitBreaker{FailureRate: 0.5, MinimumCalls: 10, Window: time.Minute}
+	record := func(failed bool) {
+		done, err := cb.Allow()
+		assert.NoError(t, err)
+		if failed {
+			done(errors.New("sample error"))
+		} else {
+			done(nil)
+		}
+	}
+
+	// Test alternating outcomes below the minimum number of calls.
+	for i := 0; i < 4; i++ {
+		record(true)
+		record(false)
+	}
+	assert.Equal(t, CircuitClosed, cb.State(), "Below minimum calls.")
+
+	// Test reaching the failure rate.
+	record(false)
+	record(true)
+	assert.Equal(t, CircuitOpen, cb.State(), "Failure rate reached.")
+
+	// Test reset.
+	cb.Reset()
+	assert.Equal(t, CircuitClosed, cb.State())
+	record(true)
+	assert.Equal(t, CircuitClosed, cb.State(), "Counts cleared.")
+}
+
+// TestRetryCircuitBreaker tests Retry() with a CircuitBreaker.
+func TestRetryCircuitBreaker(t *testing.T) {
+	cb := NewCircuitBreaker()
+	cb.ConsecutiveFailures = 2
+	attempts := 0
+	start := time.Now()
+	err := RetryFunc(context.Background(), RetryPolicy{Backoff: ConstantBackoff{Interval: 10 * time.Millisecond}, CircuitBreaker: cb, MaxAttempts: 10}, func(context.Context) error {
+		attempts++
+		return errors.New("sample error")
+	})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, attempts, "Stopped when breaker opened.")
+	assert.True(t, time.Since(start) < time.Second)
+
+	// Test an open breaker failing without sleeping.
+	start = time.Now()
+	err = RetryFunc(context.Background(), RetryPolicy{Backoff: ConstantBackoff{Interval: time.Minute}, CircuitBreaker: cb}, func(context.Context) error {
+		attempts++
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, attempts)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+// TestCircuitBreakerClock tests CircuitBreaker on a fake clock.
+func TestCircuitBreakerClock(t *testing.T) {
+	ctx := context.Background()
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker()
+	cb.Clock = clock
+	cb.ConsecutiveFailures = 1
+	cb.OpenTimeout = time.Minute
+
+	// Test the open timeout following the clock.
+	_ = cb.Execute(ctx, func(context.Context) error {
+		return errors.New("sample error")
+	})
+	assert.Equal(t, CircuitOpen, cb.State())
+	clock.Advance(59 * time.Second)
+	assert.Equal(t, CircuitOpen, cb.State())
+	clock.Advance(time.Second)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+
+	// Test a cancelled probe leaving the breaker half-open and freeing its probe.
+	err := cb.Execute(ctx, func(context.Context) error {
+		return context.Canceled
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	assert.NoError(t, cb.Execute(ctx, func(context.Context) error {
+		return nil
+	}))
+	assert.Equal(t, CircuitClosed, cb.State())
+}
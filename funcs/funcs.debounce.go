@@ -0,0 +1,122 @@
+package funcs
+
+import (
+	"sync"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+// Debounce returns a function that calls method once calls to it have stopped for wait, and a function that cancels any pending call.
+// method runs on its own goroutine.
+func Debounce(wait time.Duration, method func()) (debounced func(), cancel func()) {
+	return DebounceWith(timeUtils.RealClock, wait, method)
+}
+
+// DebounceWith returns a function that calls method once calls to it have stopped for wait on clock, and a function that cancels any pending call.
+// method runs on its own goroutine.
+func DebounceWith(clock timeUtils.Clock, wait time.Duration, method func()) (debounced func(), cancel func()) {
+	var lock sync.Mutex
+	var stop func() bool
+
+	debounced = func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if stop != nil {
+			stop()
+		}
+		stop = afterFunc(clock, wait, method)
+	}
+	cancel = func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if stop != nil {
+			stop()
+		}
+	}
+
+	return debounced, cancel
+}
+
+// Throttle returns a function that calls method at most once per interval.
+// The first call runs immediately; calls during the interval are collapsed into one call at its end, on its own goroutine.
+func Throttle(interval time.Duration, method func()) func() {
+	return ThrottleWith(timeUtils.RealClock, interval, method)
+}
+
+// ThrottleWith returns a function that calls method at most once per interval on clock.
+// The first call runs immediately; calls during the interval are collapsed into one call at its end, on its own goroutine.
+func ThrottleWith(clock timeUtils.Clock, interval time.Duration, method func()) func() {
+	var lock sync.Mutex
+	var last time.Time
+	pending := false
+
+	return func() {
+		lock.Lock()
+		if pending {
+			lock.Unlock()
+			return
+		}
+		now := clock.Now()
+		elapsed := now.Sub(last)
+		if last.IsZero() || elapsed >= interval {
+			last = now
+			lock.Unlock()
+			method()
+			return
+		}
+
+		// Schedule a trailing call.
+		pending = true
+		afterFunc(clock, interval-elapsed, func() {
+			lock.Lock()
+			pending = false
+			last = clock.Now()
+			lock.Unlock()
+			method()
+		})
+		lock.Unlock()
+	}
+}
+
+// afterFunc calls method on its own goroutine once duration has passed on clock, returning a function that prevents the call and reports whether it did so.
+// The timer firing and stop are settled under a lock, so method never starts after stop has returned true.
+func afterFunc(clock timeUtils.Clock, duration time.Duration, method func()) (stop func() bool) {
+	timer := clock.NewTimer(duration)
+	var lock sync.Mutex
+	settled := false
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-timer.C():
+		case <-stopped:
+			return
+		}
+
+		// Check for a stop that raced with the timer.
+		lock.Lock()
+		if settled {
+			lock.Unlock()
+			return
+		}
+		settled = true
+		lock.Unlock()
+		method()
+	}()
+
+	return func() bool {
+		timer.Stop()
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if settled {
+			return false
+		}
+		settled = true
+		close(stopped)
+		return true
+	}
+}
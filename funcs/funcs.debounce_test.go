@@ -0,0 +1,125 @@
+package funcs
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDebounce tests Debounce().
+func TestDebounce(t *testing.T) {
+	var calls int32
+	debounced, cancel := Debounce(20*time.Millisecond, func() {
+		atomic.AddInt32(&calls, 1)
+	})
+
+	// Test a burst of calls settling into one.
+	for i := 0; i < 5; i++ {
+		debounced()
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls), "Not yet settled.")
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 1
+	}, time.Second, time.Millisecond)
+
+	// Test cancellation.
+	debounced()
+	cancel()
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Cancelled.")
+}
+
+// TestThrottle tests Throttle().
+func TestThrottle(t *testing.T) {
+	var calls int32
+	throttled := Throttle(30*time.Millisecond, func() {
+		atomic.AddInt32(&calls, 1)
+	})
+
+	// Test the leading call and one trailing call.
+	for i := 0; i < 10; i++ {
+		throttled()
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Leading call.")
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 2
+	}, time.Second, time.Millisecond, "Trailing call.")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "Calls collapsed.")
+}
+
+// TestDebounceWith tests DebounceWith().
+func TestDebounceWith(t *testing.T) {
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	calls := make(chan struct{}, 10)
+	debounced, cancel := DebounceWith(clock, time.Minute, func() {
+		calls <- struct{}{}
+	})
+
+	// Test calls restarting the wait.
+	debounced()
+	clock.Advance(30 * time.Second)
+	debounced()
+	clock.Advance(59 * time.Second)
+	assert.Len(t, calls, 0, "Not yet settled.")
+	clock.Advance(time.Second)
+	<-calls
+
+	// Test cancellation.
+	debounced()
+	cancel()
+	assert.Equal(t, 0, clock.Waiters())
+	clock.Advance(time.Hour)
+	assert.Len(t, calls, 0, "Cancelled.")
+
+	// Test cancelling after the timer fires but before the pending call starts, which a single processor makes deterministic.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	for i := 0; i < 100; i++ {
+		debounced()
+		clock.Advance(time.Minute)
+		cancel()
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, calls, 0, "Cancelled after the timer fired.")
+
+	// Test replacing a burst whose timer has fired.
+	for i := 0; i < 5; i++ {
+		debounced()
+		clock.Advance(time.Minute)
+		debounced()
+		clock.Advance(time.Minute)
+		<-calls
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, calls, 0, "Replaced bursts not called.")
+}
+
+// TestThrottleWith tests ThrottleWith().
+func TestThrottleWith(t *testing.T) {
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	calls := make(chan struct{}, 10)
+	throttled := ThrottleWith(clock, time.Minute, func() {
+		calls <- struct{}{}
+	})
+
+	// Test the leading call and one trailing call at the end of the interval.
+	for i := 0; i < 10; i++ {
+		throttled()
+	}
+	assert.Len(t, calls, 1, "Leading call.")
+	clock.Advance(59 * time.Second)
+	assert.Len(t, calls, 1, "Trailing call waits for the interval.")
+	clock.Advance(time.Second)
+	<-calls
+	<-calls
+
+	// Test a call after the interval running immediately.
+	clock.Advance(time.Minute)
+	throttled()
+	assert.Len(t, calls, 1, "Interval passed.")
+}
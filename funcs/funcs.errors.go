@@ -0,0 +1,156 @@
+package funcs
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRetryErrors is the number of attempt errors kept by RetryError.
+	maxRetryErrors = 100
+)
+
+// RetryError aggregates the errors returned by each attempt of a retried method.
+type RetryError struct {
+	Attempts int     // Number of attempts that failed.
+	Errors   []error // Errors from each failed attempt in order, limited to the most recent 100.
+}
+
+// permanentError marks an error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+// retryAfterError marks an error that should be retried after a delay.
+type retryAfterError struct {
+	delay time.Duration
+	err   error
+}
+
+// throttledError marks an error caused by rate limiting.
+type throttledError struct {
+	err error
+}
+
+// Permanent marks an error as permanent, so that it is not retried. It returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// RetryAfter marks an error as retryable after delay, which overrides the backoff delay. It returns nil if err is nil.
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+
+	return &retryAfterError{delay: delay, err: err}
+}
+
+// Throttled marks an error as caused by rate limiting. Throttled errors are retried with twice the backoff delay. It returns nil if err is nil.
+func Throttled(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &throttledError{err: err}
+}
+
+// IsPermanent reports whether an error, or any error it wraps, was marked by Permanent.
+func IsPermanent(err error) bool {
+	var target *permanentError
+	return errors.As(err, &target)
+}
+
+// IsThrottled reports whether an error, or any error it wraps, was marked by Throttled.
+func IsThrottled(err error) bool {
+	var target *throttledError
+	return errors.As(err, &target)
+}
+
+// RetryAfterDelay returns the delay requested by RetryAfter for an error, if any.
+func RetryAfterDelay(err error) (time.Duration, bool) {
+	var target *retryAfterError
+	if !errors.As(err, &target) {
+		return 0, false
+	}
+
+	return target.delay, true
+}
+
+// Error returns the last error, preceded by the number of attempts if there was more than one.
+func (e *RetryError) Error() string {
+	if len(e.Errors) == 0 {
+		return "retry failed"
+	}
+	lastErr := e.Errors[len(e.Errors)-1]
+	if e.Attempts == 1 {
+		return lastErr.Error()
+	}
+
+	// Summarize earlier errors.
+	previous := make([]string, 0, len(e.Errors)-1)
+	for _, err := range e.Errors[:len(e.Errors)-1] {
+		previous = append(previous, err.Error())
+	}
+
+	return strconv.Itoa(e.Attempts) + " attempts failed: " + lastErr.Error() + " (previous errors: " + strings.Join(previous, "; ") + ")"
+}
+
+// Last returns the error from the final attempt.
+func (e *RetryError) Last() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	return e.Errors[len(e.Errors)-1]
+}
+
+// Unwrap returns the errors from each attempt, for use with errors.Is and errors.As.
+func (e *RetryError) Unwrap() []error {
+	return e.Errors
+}
+
+// add records the error from an attempt.
+func (e *RetryError) add(err error) {
+	e.Attempts++
+	if len(e.Errors) == maxRetryErrors {
+		e.Errors = append(e.Errors[:0], e.Errors[1:]...)
+	}
+	e.Errors = append(e.Errors, err)
+}
+
+// Error returns the wrapped error's message.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the wrapped error's message.
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the wrapped error's message.
+func (e *throttledError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *throttledError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,55 @@
+package funcs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestErrorWrappers tests Permanent(), RetryAfter() and Throttled().
+func TestErrorWrappers(t *testing.T) {
+	baseErr := errors.New("sample error")
+
+	// Test nil errors.
+	assert.NoError(t, Permanent(nil))
+	assert.NoError(t, RetryAfter(nil, time.Second))
+	assert.NoError(t, Throttled(nil))
+
+	// Test markers survive further wrapping.
+	err := fmt.Errorf("context: %w", Throttled(RetryAfter(baseErr, time.Second)))
+	assert.True(t, IsThrottled(err))
+	assert.False(t, IsPermanent(err))
+	delay, ok := RetryAfterDelay(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, delay)
+	assert.ErrorIs(t, err, baseErr)
+	assert.Equal(t, "context: sample error", err.Error())
+
+	err = Permanent(baseErr)
+	assert.True(t, IsPermanent(err))
+	_, ok = RetryAfterDelay(err)
+	assert.False(t, ok)
+}
+
+// TestRetryError tests RetryError.
+func TestRetryError(t *testing.T) {
+	firstErr := errors.New("first")
+	retryErr := &RetryError{}
+	retryErr.add(firstErr)
+	assert.Equal(t, "first", retryErr.Error())
+	retryErr.add(errors.New("second"))
+	retryErr.add(errors.New("third"))
+	assert.Equal(t, "3 attempts failed: third (previous errors: first; second)", retryErr.Error())
+	assert.EqualError(t, retryErr.Last(), "third")
+	assert.ErrorIs(t, retryErr, firstErr)
+
+	// Test the error limit.
+	for i := 0; i < 2*maxRetryErrors; i++ {
+		retryErr.add(errors.New("repeated"))
+	}
+	assert.Equal(t, 2*maxRetryErrors+3, retryErr.Attempts)
+	assert.Len(t, retryErr.Errors, maxRetryErrors)
+}
@@ -8,45 +8,75 @@ import (
 )
 
 // RetryN calls method up to retries times with exponential backoff until no error is returned.
+// To cancel retries or configure backoff, use Retry.
 func RetryN(method func() (bool, error), retries int) error {
+	return RetryNWith(timeUtils.RealClock, timeUtils.DefaultJitterSource, nil, method, retries)
+}
+
+// RetryNWith calls method up to retries times with exponential backoff on clock, drawing jitter from jitterSource, until no error is returned.
+// If observer is not nil, it receives an event for each attempt, backoff and outcome.
+func RetryNWith(clock timeUtils.Clock, jitterSource timeUtils.JitterSource, observer RetryObserver, method func() (bool, error), retries int) error {
 	// Ensure there is a retry.
 	if retries < 1 {
 		retries = 1
 	}
 
+	notify := retryNotifier(clock, observer)
 	var isFinal bool
 	var err error
 	for i := 0; i < retries; i++ {
 		isFinal, err = method()
+		notify(RetryEventAttempt, i+1, 0, err)
+		if err == nil {
+			notify(RetryEventSuccess, i+1, 0, nil)
+			return nil
+		}
 		if isFinal {
+			notify(RetryEventGiveUp, i+1, 0, err)
 			return err
 		}
-		if err == nil {
-			return nil
+		if i == retries-1 {
+			// Don't wait after the last attempt.
+			break
 		}
 
-		timeUtils.SleepIncremental(i)
+		delay := timeUtils.IncrementalDelay(jitterSource, i)
+		notify(RetryEventBackoff, i+1, delay, err)
+		clock.Sleep(delay)
 	}
+	notify(RetryEventGiveUp, retries, 0, err)
 
 	return err
 }
 
 // RetryUnlimited calls method as many times as required with constant backoff until no error is returned.
+// To cancel retries or configure backoff, use Retry.
 func RetryUnlimited(method func() (bool, error)) error {
+	return RetryUnlimitedWith(timeUtils.RealClock, nil, method)
+}
+
+// RetryUnlimitedWith calls method as many times as required with constant backoff on clock until no error is returned.
+// If observer is not nil, it receives an event for each attempt, backoff and outcome.
+func RetryUnlimitedWith(clock timeUtils.Clock, observer RetryObserver, method func() (bool, error)) error {
+	notify := retryNotifier(clock, observer)
 	var isFinal bool
 	var err error
 	attempts := 0
 	delay := time.Second
 	for {
 		isFinal, err = method()
-		if isFinal {
-			return err
-		}
+		attempts++
+		notify(RetryEventAttempt, attempts, 0, err)
 		if err == nil {
+			notify(RetryEventSuccess, attempts, 0, nil)
 			return nil
 		}
+		if isFinal {
+			notify(RetryEventGiveUp, attempts, 0, err)
+			return err
+		}
 
-		attempts++
-		time.Sleep(delay)
+		notify(RetryEventBackoff, attempts, delay, err)
+		clock.Sleep(delay)
 	}
 }
@@ -0,0 +1,108 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+// hedgeResult defines the outcome of one hedged attempt.
+type hedgeResult[T any] struct {
+	attempt int
+	err     error
+	value   T
+}
+
+// Hedge calls method, starting another attempt each time delay passes without a success, up to maxParallel attempts.
+// An attempt that fails also starts the next one immediately. The first successful result is returned with the index of the attempt that produced it, starting at zero, and the contexts of the other attempts are cancelled.
+// If every attempt fails, the index is -1 and the error joins each attempt's error.
+func Hedge[T any](ctx context.Context, delay time.Duration, maxParallel int, method func(ctx context.Context, attempt int) (T, error)) (T, int, error) {
+	return HedgeWith(ctx, timeUtils.RealClock, delay, maxParallel, method)
+}
+
+// HedgeWith calls method as Hedge does, measuring the delay between attempts on clock.
+func HedgeWith[T any](ctx context.Context, clock timeUtils.Clock, delay time.Duration, maxParallel int, method func(ctx context.Context, attempt int) (T, error)) (T, int, error) {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Results are buffered so that losing attempts never block.
+	results := make(chan hedgeResult[T], maxParallel)
+	launched := 0
+	outstanding := 0
+	var timer timeUtils.Timer
+	var timerC <-chan time.Time
+	launch := func() {
+		attempt := launched
+		launched++
+		outstanding++
+		go func() {
+			value, err := method(hedgeCtx, attempt)
+			results <- hedgeResult[T]{attempt: attempt, err: err, value: value}
+		}()
+
+		// Schedule the next attempt.
+		if timer != nil {
+			timer.Stop()
+		}
+		timerC = nil
+		if launched < maxParallel {
+			timer = clock.NewTimer(delay)
+			timerC = timer.C()
+		}
+	}
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	launch()
+	var zero T
+	errs := []error{}
+	for {
+		select {
+		case result := <-results:
+			outstanding--
+			if result.err == nil {
+				return result.value, result.attempt, nil
+			}
+			errs = append(errs, result.err)
+			if launched < maxParallel {
+				launch()
+			} else if outstanding == 0 {
+				return zero, -1, errors.Join(errs...)
+			}
+		case <-timerC:
+			launch()
+		case <-ctx.Done():
+			return zero, -1, ctx.Err()
+		}
+	}
+}
+
+// WithTimeout calls method with a context that is cancelled after timeout, returning context.DeadlineExceeded as soon as the timeout passes even if method has not yet returned.
+func WithTimeout[T any](ctx context.Context, timeout time.Duration, method func(ctx context.Context) (T, error)) (T, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Results are buffered so that an abandoned call never blocks.
+	results := make(chan hedgeResult[T], 1)
+	go func() {
+		value, err := method(timeoutCtx)
+		results <- hedgeResult[T]{err: err, value: value}
+	}()
+
+	select {
+	case result := <-results:
+		return result.value, result.err
+	case <-timeoutCtx.Done():
+		var zero T
+		return zero, timeoutCtx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHedge tests Hedge().
+func TestHedge(t *testing.T) {
+	ctx := context.Background()
+
+	// Test a fast first attempt.
+	var launched int32
+	value, winner, err := Hedge(ctx, 50*time.Millisecond, 3, func(context.Context, int) (string, error) {
+		atomic.AddInt32(&launched, 1)
+		return "first", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "first", value)
+	assert.Equal(t, 0, winner)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&launched), "No hedged attempts.")
+
+	// Test a slow first attempt losing to a hedged attempt, and being cancelled.
+	cancelled := make(chan int, 3)
+	value, winner, err = Hedge(ctx, 10*time.Millisecond, 3, func(attemptCtx context.Context, attempt int) (string, error) {
+		if attempt == 0 {
+			<-attemptCtx.Done()
+			cancelled <- attempt
+			return "", attemptCtx.Err()
+		}
+		return "hedged", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hedged", value)
+	assert.Equal(t, 1, winner)
+	select {
+	case attempt := <-cancelled:
+		assert.Equal(t, 0, attempt, "Loser cancelled.")
+	case <-time.After(time.Second):
+		assert.Fail(t, "Loser not cancelled.")
+	}
+
+	// Test a failure starting the next attempt immediately.
+	start := time.Now()
+	_, winner, err = Hedge(ctx, time.Minute, 2, func(_ context.Context, attempt int) (int, error) {
+		if attempt == 0 {
+			return 0, errors.New("sample error")
+		}
+		return attempt, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, winner)
+	assert.True(t, time.Since(start) < time.Second)
+
+	// Test all attempts failing.
+	firstErr := errors.New("first error")
+	_, winner, err = Hedge(ctx, time.Millisecond, 2, func(_ context.Context, attempt int) (int, error) {
+		if attempt == 0 {
+			return 0, firstErr
+		}
+		return 0, errors.New("second error")
+	})
+	assert.ErrorIs(t, err, firstErr)
+	assert.Contains(t, err.Error(), "second error")
+	assert.Equal(t, -1, winner)
+
+	// Test cancellation.
+	cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, winner, err = Hedge(cancelCtx, time.Millisecond, 2, func(attemptCtx context.Context, _ int) (int, error) {
+		<-attemptCtx.Done()
+		return 0, attemptCtx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, -1, winner)
+}
+
+// TestHedgeWith tests HedgeWith().
+func TestHedgeWith(t *testing.T) {
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	release := make(chan struct{})
+	launched := make(chan int, 3)
+	type result struct {
+		err    error
+		value  int
+		winner int
+	}
+	done := make(chan result)
+	go func() {
+		value, winner, err := HedgeWith(context.Background(), clock, time.Minute, 3, func(attemptCtx context.Context, attempt int) (int, error) {
+			launched <- attempt
+			if attempt == 0 {
+				<-attemptCtx.Done()
+				return 0, attemptCtx.Err()
+			}
+			<-release
+			return attempt, nil
+		})
+		done <- result{err: err, value: value, winner: winner}
+	}()
+
+	// Test hedged attempts starting only as the clock advances.
+	assert.Equal(t, 0, <-launched)
+	clock.BlockUntil(1)
+	assert.Len(t, launched, 0, "Waiting for the delay.")
+	clock.Advance(time.Minute)
+	assert.Equal(t, 1, <-launched)
+	close(release)
+	hedged := <-done
+	assert.NoError(t, hedged.err)
+	assert.Equal(t, 1, hedged.value)
+	assert.Equal(t, 1, hedged.winner)
+}
+
+// TestWithTimeout tests WithTimeout().
+func TestWithTimeout(t *testing.T) {
+	// Test a call completing in time.
+	value, err := WithTimeout(context.Background(), time.Second, func(context.Context) (int, error) {
+		return 42, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	// Test a call ignoring its context.
+	start := time.Now()
+	_, err = WithTimeout(context.Background(), 20*time.Millisecond, func(context.Context) (int, error) {
+		time.Sleep(200 * time.Millisecond)
+		return 42, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(start) < 150*time.Millisecond, "Returned at timeout.")
+}
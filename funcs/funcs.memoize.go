@@ -0,0 +1,177 @@
+package funcs
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	hashUtils "github.com/bertjohnson/util/hash"
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+// MemoizeOptions defines how Memoize caches results.
+type MemoizeOptions struct {
+	Clock                timeUtils.Clock // Clock used for expiry; defaults to time.RealClock.
+	MaxEntries           int             // Maximum number of cached results, evicting the least recently used; unlimited if zero.
+	StaleWhileRevalidate time.Duration   // How long after expiry a result may still be returned while it is refreshed in the background.
+	TTL                  time.Duration   // How long results are cached; forever if zero.
+}
+
+// Memoized caches the results of a function by argument. Concurrent calls for the same argument share one execution.
+type Memoized[K any, V any] struct {
+	entries map[uint64]*list.Element
+	group   Group[V]
+	lock    sync.Mutex
+	lru     *list.List
+	method  func(ctx context.Context, key K) (V, error)
+	options MemoizeOptions
+}
+
+// memoizedEntry defines a cached result.
+type memoizedEntry[V any] struct {
+	expires    time.Time
+	hash       uint64
+	refreshing bool
+	value      V
+}
+
+// Memoize returns a cache of method's results, keyed by hash.HighwayHashUInt64 of its argument so that structs may be used. Errors are not cached.
+func Memoize[K any, V any](method func(ctx context.Context, key K) (V, error), options MemoizeOptions) *Memoized[K, V] {
+	return &Memoized[K, V]{
+		entries: map[uint64]*list.Element{},
+		lru:     list.New(),
+		method:  method,
+		options: options,
+	}
+}
+
+// Forget removes the cached result for key.
+func (m *Memoized[K, V]) Forget(ctx context.Context, key K) error {
+	hash, err := hashUtils.HighwayHashUInt64(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if element, ok := m.entries[hash]; ok {
+		m.lru.Remove(element)
+		delete(m.entries, hash)
+	}
+
+	return nil
+}
+
+// Get returns the cached result for key, calling the function if it is missing or expired.
+// Within the stale-while-revalidate period, the expired result is returned and refreshed in the background.
+// A call runs with the values of the context of the caller that started it, but is not cancelled with it, so that other callers waiting for the same key are unaffected; each caller stops waiting when its own context is done.
+// Background refreshes use context.Background().
+func (m *Memoized[K, V]) Get(ctx context.Context, key K) (V, error) {
+	hash, err := hashUtils.HighwayHashUInt64(ctx, key)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	// Check cache.
+	m.lock.Lock()
+	if element, ok := m.entries[hash]; ok {
+		entry := element.Value.(*memoizedEntry[V])
+		now := m.now()
+		if m.options.TTL <= 0 || now.Before(entry.expires) {
+			m.lru.MoveToFront(element)
+			m.lock.Unlock()
+			return entry.value, nil
+		}
+		if m.options.StaleWhileRevalidate > 0 && now.Before(entry.expires.Add(m.options.StaleWhileRevalidate)) {
+			m.lru.MoveToFront(element)
+			if !entry.refreshing {
+				entry.refreshing = true
+				go m.refresh(hash, key)
+			}
+			m.lock.Unlock()
+			return entry.value, nil
+		}
+	}
+	m.lock.Unlock()
+
+	callCtx := context.WithoutCancel(ctx)
+	value, _, err := m.group.do(ctx, hash, func() (V, error) {
+		return m.call(callCtx, hash, key)
+	})
+
+	return value, err
+}
+
+// Len returns the number of cached results.
+func (m *Memoized[K, V]) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.lru.Len()
+}
+
+// Purge removes all cached results.
+func (m *Memoized[K, V]) Purge() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.entries = map[uint64]*list.Element{}
+	m.lru.Init()
+}
+
+// call calls the function and caches a successful result.
+func (m *Memoized[K, V]) call(ctx context.Context, hash uint64, key K) (V, error) {
+	value, err := m.method(ctx, key)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	element, ok := m.entries[hash]
+	if err != nil {
+		if ok {
+			element.Value.(*memoizedEntry[V]).refreshing = false
+		}
+		return value, err
+	}
+
+	// Store result.
+	entry := &memoizedEntry[V]{hash: hash, value: value}
+	if m.options.TTL > 0 {
+		entry.expires = m.now().Add(m.options.TTL)
+	}
+	if ok {
+		element.Value = entry
+		m.lru.MoveToFront(element)
+	} else {
+		m.entries[hash] = m.lru.PushFront(entry)
+	}
+
+	// Evict least recently used.
+	for m.options.MaxEntries > 0 && m.lru.Len() > m.options.MaxEntries {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoizedEntry[V]).hash)
+	}
+
+	return value, nil
+}
+
+// now returns the current time from the cache's clock.
+func (m *Memoized[K, V]) now() time.Time {
+	if m.options.Clock == nil {
+		return time.Now()
+	}
+
+	return m.options.Clock.Now()
+}
+
+// refresh recalculates a stale result in the background.
+func (m *Memoized[K, V]) refresh(hash uint64, key K) {
+	ctx := context.Background()
+	m.group.do(ctx, hash, func() (V, error) { // nolint: errcheck
+		return m.call(ctx, hash, key)
+	})
+}
@@ -0,0 +1,164 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoize tests Memoize().
+func TestMemoize(t *testing.T) {
+	ctx := context.Background()
+	type key struct {
+		Name string
+		ID   int
+	}
+	var calls int32
+	memoized := Memoize(func(_ context.Context, k key) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if k.ID < 0 {
+			return "", errors.New("invalid ID")
+		}
+		time.Sleep(5 * time.Millisecond)
+		return k.Name, nil
+	}, MemoizeOptions{MaxEntries: 2, TTL: 50 * time.Millisecond})
+
+	// Test caching and coalescing.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := memoized.Get(ctx, key{Name: "a", ID: 1})
+			assert.NoError(t, err)
+			assert.Equal(t, "a", value)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	value, err := memoized.Get(ctx, key{Name: "a", ID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "a", value)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Cached.")
+
+	// Test errors not being cached.
+	_, err = memoized.Get(ctx, key{ID: -1})
+	assert.Error(t, err)
+	_, err = memoized.Get(ctx, key{ID: -1})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// Test LRU eviction.
+	_, _ = memoized.Get(ctx, key{Name: "b", ID: 2})
+	_, _ = memoized.Get(ctx, key{Name: "a", ID: 1})
+	_, _ = memoized.Get(ctx, key{Name: "c", ID: 3})
+	assert.Equal(t, 2, memoized.Len())
+	atomic.StoreInt32(&calls, 0)
+	_, _ = memoized.Get(ctx, key{Name: "a", ID: 1})
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls), "Recently used entry kept.")
+	_, _ = memoized.Get(ctx, key{Name: "b", ID: 2})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Least recently used entry evicted.")
+
+	// Test expiry.
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&calls, 0)
+	_, _ = memoized.Get(ctx, key{Name: "b", ID: 2})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expired.")
+
+	// Test forget and purge.
+	assert.NoError(t, memoized.Forget(ctx, key{Name: "b", ID: 2}))
+	assert.Equal(t, 1, memoized.Len())
+	_, _ = memoized.Get(ctx, key{Name: "b", ID: 2})
+	memoized.Purge()
+	assert.Equal(t, 0, memoized.Len())
+}
+
+// TestMemoizeClock tests Memoize() expiry on a fake clock.
+func TestMemoizeClock(t *testing.T) {
+	ctx := context.Background()
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	var calls int32
+	memoized := Memoize(func(_ context.Context, key int) (int32, error) {
+		return atomic.AddInt32(&calls, 1), nil
+	}, MemoizeOptions{Clock: clock, TTL: time.Hour})
+
+	// Test expiry as the clock advances.
+	value, err := memoized.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), value)
+	clock.Advance(59 * time.Minute)
+	value, err = memoized.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), value, "Cached.")
+	clock.Advance(time.Minute)
+	value, err = memoized.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), value, "Expired.")
+}
+
+// TestMemoizeCancellation tests that cancelling the caller that started a call does not fail other callers.
+func TestMemoizeCancellation(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	memoized := Memoize(func(ctx context.Context, key int) (int, error) {
+		close(started)
+		select {
+		case <-release:
+			return key * 2, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}, MemoizeOptions{})
+
+	// Start a call and cancel its caller while another caller waits.
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := memoized.Get(firstCtx, 1)
+		firstErr <- err
+	}()
+	<-started
+	secondValue := make(chan int)
+	go func() {
+		value, err := memoized.Get(context.Background(), 1)
+		assert.NoError(t, err)
+		secondValue <- value
+	}()
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+	assert.Equal(t, 2, <-secondValue)
+	assert.Equal(t, 1, memoized.Len())
+}
+
+// TestMemoizeStaleWhileRevalidate tests Memoize() with stale-while-revalidate.
+func TestMemoizeStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	var version int32
+	memoized := Memoize(func(context.Context, string) (int32, error) {
+		time.Sleep(20 * time.Millisecond)
+		return atomic.AddInt32(&version, 1), nil
+	}, MemoizeOptions{StaleWhileRevalidate: time.Second, TTL: 10 * time.Millisecond})
+
+	value, err := memoized.Get(ctx, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), value)
+
+	// Test a stale value returned immediately while refreshing.
+	time.Sleep(15 * time.Millisecond)
+	start := time.Now()
+	value, err = memoized.Get(ctx, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), value, "Stale value.")
+	assert.True(t, time.Since(start) < 10*time.Millisecond, "Returned without waiting.")
+	assert.Eventually(t, func() bool {
+		value, _ := memoized.Get(ctx, "token")
+		return value == 2
+	}, time.Second, 5*time.Millisecond, "Refreshed value.")
+}
@@ -0,0 +1,223 @@
+package funcs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Retry event types.
+const (
+	RetryEventAttempt RetryEventType = iota // An attempt completed.
+	RetryEventBackoff                       // A backoff delay is starting.
+	RetryEventGiveUp                        // Retrying stopped without success.
+	RetryEventSuccess                       // An attempt succeeded.
+)
+
+// RetryEventType identifies a retry event.
+type RetryEventType int
+
+// RetryEvent describes a step of a retried method.
+type RetryEvent struct {
+	Attempt int            // Attempt number, starting at 1.
+	Delay   time.Duration  // Backoff delay before the next attempt, for backoff events.
+	Elapsed time.Duration  // Time since the first attempt started.
+	Err     error          // Attempt error for attempt events, or the returned error for give-up events.
+	Type    RetryEventType // Event type.
+}
+
+// RetryObserver receives events from Retry, RetryFunc, RetryNWith and RetryUnlimitedWith. Methods are called synchronously from the retrying goroutine.
+type RetryObserver interface {
+	OnAttempt(event RetryEvent) // Called after each attempt, successful or not.
+	OnBackoff(event RetryEvent) // Called before each backoff delay.
+	OnGiveUp(event RetryEvent)  // Called when retrying stops without success.
+	OnSuccess(event RetryEvent) // Called when an attempt succeeds.
+}
+
+// RetryStats collects retry events in memory, for metrics and tests. The zero value is ready to use and safe for concurrent use.
+type RetryStats struct {
+	counts RetryCounts
+	events []RetryEvent
+	lock   sync.Mutex
+}
+
+// RetryCounts summarizes retry events collected by RetryStats.
+type RetryCounts struct {
+	Attempts     int           // Number of attempts.
+	Backoffs     int           // Number of backoff delays.
+	Failures     int           // Number of failed attempts.
+	GiveUps      int           // Number of retries that stopped without success.
+	LastError    error         // Most recent attempt error.
+	Successes    int           // Number of retries that succeeded.
+	TotalBackoff time.Duration // Sum of backoff delays.
+}
+
+// SlogRetryObserver logs retry events with a structured logger.
+// Failed attempts are logged at warning level, backoffs at debug level, give-ups at error level and successes at info level.
+type SlogRetryObserver struct {
+	Logger    *slog.Logger // Logger; defaults to slog.Default().
+	Operation string       // Name of the retried operation, logged as the "operation" attribute.
+}
+
+// multiRetryObserver sends events to several observers.
+type multiRetryObserver []RetryObserver
+
+// MultiRetryObserver returns an observer that sends each event to all of observers in order.
+func MultiRetryObserver(observers ...RetryObserver) RetryObserver {
+	return multiRetryObserver(observers)
+}
+
+// String returns the name of a retry event type.
+func (t RetryEventType) String() string {
+	switch t {
+	case RetryEventAttempt:
+		return "attempt"
+	case RetryEventBackoff:
+		return "backoff"
+	case RetryEventGiveUp:
+		return "give up"
+	case RetryEventSuccess:
+		return "success"
+	default:
+		return "unknown"
+	}
+}
+
+// Counts returns a summary of the collected events.
+func (s *RetryStats) Counts() RetryCounts {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.counts
+}
+
+// Events returns a copy of the collected events in order.
+func (s *RetryStats) Events() []RetryEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]RetryEvent{}, s.events...)
+}
+
+// OnAttempt records an attempt.
+func (s *RetryStats) OnAttempt(event RetryEvent) {
+	s.record(event, func(counts *RetryCounts) {
+		counts.Attempts++
+		if event.Err != nil {
+			counts.Failures++
+			counts.LastError = event.Err
+		}
+	})
+}
+
+// OnBackoff records a backoff delay.
+func (s *RetryStats) OnBackoff(event RetryEvent) {
+	s.record(event, func(counts *RetryCounts) {
+		counts.Backoffs++
+		counts.TotalBackoff += event.Delay
+	})
+}
+
+// OnGiveUp records a retry that stopped without success.
+func (s *RetryStats) OnGiveUp(event RetryEvent) {
+	s.record(event, func(counts *RetryCounts) {
+		counts.GiveUps++
+	})
+}
+
+// OnSuccess records a successful retry.
+func (s *RetryStats) OnSuccess(event RetryEvent) {
+	s.record(event, func(counts *RetryCounts) {
+		counts.Successes++
+	})
+}
+
+// Reset clears the collected events.
+func (s *RetryStats) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.counts = RetryCounts{}
+	s.events = nil
+}
+
+// record appends an event and updates the counts.
+func (s *RetryStats) record(event RetryEvent, update func(counts *RetryCounts)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.events = append(s.events, event)
+	update(&s.counts)
+}
+
+// OnAttempt logs a failed attempt.
+func (o SlogRetryObserver) OnAttempt(event RetryEvent) {
+	if event.Err != nil {
+		o.log(slog.LevelWarn, "retry attempt failed", event, slog.String("error", event.Err.Error()))
+	}
+}
+
+// OnBackoff logs a backoff delay.
+func (o SlogRetryObserver) OnBackoff(event RetryEvent) {
+	o.log(slog.LevelDebug, "retry backing off", event, slog.Duration("delay", event.Delay))
+}
+
+// OnGiveUp logs a retry that stopped without success.
+func (o SlogRetryObserver) OnGiveUp(event RetryEvent) {
+	attrs := []slog.Attr{}
+	if event.Err != nil {
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+	o.log(slog.LevelError, "retry gave up", event, attrs...)
+}
+
+// OnSuccess logs a successful retry.
+func (o SlogRetryObserver) OnSuccess(event RetryEvent) {
+	o.log(slog.LevelInfo, "retry succeeded", event)
+}
+
+// log writes an event with its common attributes.
+func (o SlogRetryObserver) log(level slog.Level, message string, event RetryEvent, attrs ...slog.Attr) {
+	logger := o.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	attrs = append([]slog.Attr{
+		slog.Int("attempt", event.Attempt),
+		slog.Duration("elapsed", event.Elapsed),
+	}, attrs...)
+	if o.Operation != "" {
+		attrs = append([]slog.Attr{slog.String("operation", o.Operation)}, attrs...)
+	}
+	logger.LogAttrs(context.Background(), level, message, attrs...)
+}
+
+// OnAttempt sends an attempt event to each observer.
+func (m multiRetryObserver) OnAttempt(event RetryEvent) {
+	for _, observer := range m {
+		observer.OnAttempt(event)
+	}
+}
+
+// OnBackoff sends a backoff event to each observer.
+func (m multiRetryObserver) OnBackoff(event RetryEvent) {
+	for _, observer := range m {
+		observer.OnBackoff(event)
+	}
+}
+
+// OnGiveUp sends a give-up event to each observer.
+func (m multiRetryObserver) OnGiveUp(event RetryEvent) {
+	for _, observer := range m {
+		observer.OnGiveUp(event)
+	}
+}
+
+// OnSuccess sends a success event to each observer.
+func (m multiRetryObserver) OnSuccess(event RetryEvent) {
+	for _, observer := range m {
+		observer.OnSuccess(event)
+	}
+}
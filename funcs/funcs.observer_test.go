@@ -0,0 +1,80 @@
+package funcs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetryStats tests RetryStats.
+func TestRetryStats(t *testing.T) {
+	stats := &RetryStats{}
+	attempts := 0
+	err := RetryFunc(context.Background(), RetryPolicy{Backoff: ConstantBackoff{Interval: time.Millisecond}, Observer: stats}, func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("sample error")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	counts := stats.Counts()
+	assert.Equal(t, 3, counts.Attempts)
+	assert.Equal(t, 2, counts.Failures)
+	assert.Equal(t, 2, counts.Backoffs)
+	assert.Equal(t, 2*time.Millisecond, counts.TotalBackoff)
+	assert.Equal(t, 1, counts.Successes)
+	assert.Equal(t, 0, counts.GiveUps)
+	assert.EqualError(t, counts.LastError, "sample error")
+
+	// Test event order.
+	types := []string{}
+	for _, event := range stats.Events() {
+		types = append(types, event.Type.String())
+	}
+	assert.Equal(t, []string{"attempt", "backoff", "attempt", "backoff", "attempt", "success"}, types)
+	events := stats.Events()
+	assert.Equal(t, 3, events[len(events)-1].Attempt)
+	assert.True(t, events[len(events)-1].Elapsed >= 2*time.Millisecond)
+
+	// Test giving up.
+	stats.Reset()
+	err = RetryFunc(context.Background(), RetryPolicy{Backoff: ConstantBackoff{Interval: time.Millisecond}, MaxAttempts: 2, Observer: stats}, func(context.Context) error {
+		return errors.New("sample error")
+	})
+	assert.Error(t, err)
+	counts = stats.Counts()
+	assert.Equal(t, 2, counts.Attempts)
+	assert.Equal(t, 1, counts.GiveUps)
+	events = stats.Events()
+	assert.Equal(t, RetryEventGiveUp, events[len(events)-1].Type)
+	assert.Equal(t, err, events[len(events)-1].Err, "Give-up event carries returned error.")
+}
+
+// TestSlogRetryObserver tests SlogRetryObserver.
+func TestSlogRetryObserver(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	stats := &RetryStats{}
+	observer := MultiRetryObserver(SlogRetryObserver{Logger: logger, Operation: "fetch token"}, stats)
+	err := Retry(context.Background(), RetryPolicy{Backoff: ConstantBackoff{Interval: time.Millisecond}, MaxAttempts: 2, Observer: observer}, func(context.Context) (bool, error) {
+		return false, errors.New("sample error")
+	})
+	assert.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.Contains(t, lines[0], `level=WARN msg="retry attempt failed" operation="fetch token" attempt=1`)
+	assert.Contains(t, lines[0], `error="sample error"`)
+	assert.Contains(t, lines[1], `level=DEBUG msg="retry backing off"`)
+	assert.Contains(t, lines[1], "delay=1ms")
+	assert.Contains(t, lines[3], `level=ERROR msg="retry gave up"`)
+	assert.Equal(t, 1, stats.Counts().GiveUps, "Events sent to all observers.")
+}
@@ -0,0 +1,154 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ParallelOptions defines how Pool, ParallelMap and ParallelForEach run tasks.
+type ParallelOptions struct {
+	CollectErrors bool                           // Whether to run every task and return all errors joined; by default the first error cancels the remaining tasks and is returned alone.
+	Parallelism   int                            // Maximum number of tasks running at once; defaults to GOMAXPROCS.
+	Progress      func(completed int, total int) // Called after each task completes with the number completed and the number of items (or, for a Pool, tasks submitted so far); calls are serialized and may submit further tasks to a Pool.
+}
+
+// Pool runs tasks on a bounded number of goroutines.
+type Pool struct {
+	cancel       context.CancelFunc
+	completed    int
+	ctx          context.Context
+	errs         []error
+	lock         sync.Mutex
+	options      ParallelOptions
+	parent       context.Context
+	progressLock sync.Mutex
+	reported     int
+	slots        chan struct{}
+	submitted    int
+	total        int
+	wg           sync.WaitGroup
+}
+
+// NewPool creates a worker pool whose tasks receive a context derived from ctx.
+func NewPool(ctx context.Context, options ParallelOptions) *Pool {
+	if options.Parallelism < 1 {
+		options.Parallelism = runtime.GOMAXPROCS(0)
+	}
+
+	poolCtx, cancel := context.WithCancel(ctx)
+	return &Pool{
+		cancel:  cancel,
+		ctx:     poolCtx,
+		options: options,
+		parent:  ctx,
+		slots:   make(chan struct{}, options.Parallelism),
+	}
+}
+
+// ParallelForEach calls method for each item using a bounded number of goroutines.
+func ParallelForEach[T any](ctx context.Context, items []T, options ParallelOptions, method func(ctx context.Context, index int, item T) error) error {
+	_, err := ParallelMap(ctx, items, options, func(ctx context.Context, index int, item T) (struct{}, error) {
+		return struct{}{}, method(ctx, index, item)
+	})
+
+	return err
+}
+
+// ParallelMap calls method for each item using a bounded number of goroutines, returning the results in the order of items.
+// If an error is returned, results of tasks that failed or did not run are zero values.
+func ParallelMap[T any, R any](ctx context.Context, items []T, options ParallelOptions, method func(ctx context.Context, index int, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	pool := NewPool(ctx, options)
+	pool.total = len(items)
+	for i, item := range items {
+		index, item := i, item
+		if err := pool.Go(func(ctx context.Context) error {
+			result, err := method(ctx, index, item)
+			if err == nil {
+				results[index] = result
+			}
+			return err
+		}); err != nil {
+			break
+		}
+	}
+
+	return results, pool.Wait()
+}
+
+// Go waits for a free worker and runs task on it.
+// It returns an error without running task if the pool's context is done, either because it was cancelled or a task failed.
+func (p *Pool) Go(task func(ctx context.Context) error) error {
+	select {
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	default:
+	}
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+
+	p.lock.Lock()
+	p.submitted++
+	p.lock.Unlock()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		err := task(p.ctx)
+		<-p.slots
+		p.finish(err)
+	}()
+
+	return nil
+}
+
+// Wait waits for all running tasks, returning the first error or, if collecting errors, all errors joined.
+// If no task failed but the parent context was cancelled, the context's error is returned.
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.errs) > 0 {
+		if p.options.CollectErrors {
+			return errors.Join(p.errs...)
+		}
+		return p.errs[0]
+	}
+
+	return p.parent.Err()
+}
+
+// finish records a completed task and reports progress.
+// Progress is reported outside of the pool's lock so that the callback may submit tasks.
+func (p *Pool) finish(err error) {
+	p.lock.Lock()
+	p.completed++
+	if err != nil && (p.options.CollectErrors || len(p.errs) == 0) {
+		p.errs = append(p.errs, err)
+		if !p.options.CollectErrors {
+			p.cancel()
+		}
+	}
+	p.lock.Unlock()
+
+	if p.options.Progress != nil {
+		p.progressLock.Lock()
+		defer p.progressLock.Unlock()
+
+		p.lock.Lock()
+		total := p.total
+		if total == 0 {
+			total = p.submitted
+		}
+		p.lock.Unlock()
+		p.reported++
+		p.options.Progress(p.reported, total)
+	}
+}
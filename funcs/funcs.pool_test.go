@@ -0,0 +1,145 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPool tests Pool.
+func TestPool(t *testing.T) {
+	var running int32
+	var maxRunning int32
+	pool := NewPool(context.Background(), ParallelOptions{Parallelism: 3})
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, pool.Go(func(context.Context) error {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				previous := atomic.LoadInt32(&maxRunning)
+				if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		}))
+	}
+	assert.NoError(t, pool.Wait())
+	assert.True(t, atomic.LoadInt32(&maxRunning) <= 3, "Parallelism bounded.")
+
+	// Test the first error cancelling the rest.
+	sampleErr := errors.New("sample error")
+	pool = NewPool(context.Background(), ParallelOptions{Parallelism: 1})
+	assert.NoError(t, pool.Go(func(context.Context) error {
+		return sampleErr
+	}))
+	assert.Eventually(t, func() bool {
+		return pool.Go(func(context.Context) error { return nil }) != nil
+	}, time.Second, time.Millisecond, "Pool cancelled after error.")
+	assert.Equal(t, sampleErr, pool.Wait())
+
+	// Test progress callbacks submitting further tasks.
+	var progress [][2]int
+	pool = NewPool(context.Background(), ParallelOptions{Parallelism: 1, Progress: func(completed int, total int) {
+		progress = append(progress, [2]int{completed, total})
+		if completed < 3 {
+			assert.NoError(t, pool.Go(func(context.Context) error { return nil }))
+		}
+	}})
+	assert.NoError(t, pool.Go(func(context.Context) error { return nil }))
+	waited := make(chan error)
+	go func() {
+		waited <- pool.Wait()
+	}()
+	select {
+	case err := <-waited:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Progress callback deadlocked.")
+	}
+	assert.Equal(t, [][2]int{{1, 1}, {2, 2}, {3, 3}}, progress)
+}
+
+// TestParallelMap tests ParallelMap().
+func TestParallelMap(t *testing.T) {
+	ctx := context.Background()
+	items := []int{}
+	for i := 0; i < 100; i++ {
+		items = append(items, i)
+	}
+
+	// Test order preservation and progress.
+	var lastCompleted int
+	var progressCalls int
+	results, err := ParallelMap(ctx, items, ParallelOptions{Parallelism: 8, Progress: func(completed int, total int) {
+		progressCalls++
+		lastCompleted = completed
+		assert.Equal(t, 100, total)
+	}}, func(_ context.Context, index int, item int) (string, error) {
+		if item%7 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		return strconv.Itoa(item * 2), nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 100)
+	for i, result := range results {
+		assert.Equal(t, strconv.Itoa(i*2), result)
+	}
+	assert.Equal(t, 100, progressCalls)
+	assert.Equal(t, 100, lastCompleted)
+
+	// Test stopping on the first error.
+	var calls int32
+	_, err = ParallelMap(ctx, items, ParallelOptions{Parallelism: 2}, func(taskCtx context.Context, index int, item int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		if item == 5 {
+			return 0, errors.New("item 5 failed")
+		}
+		return item, nil
+	})
+	assert.EqualError(t, err, "item 5 failed")
+	assert.True(t, atomic.LoadInt32(&calls) < 100, "Remaining items skipped.")
+
+	// Test collecting all errors.
+	numbers, err := ParallelMap(ctx, items, ParallelOptions{CollectErrors: true, Parallelism: 4}, func(_ context.Context, index int, item int) (int, error) {
+		if item%10 == 0 {
+			return 0, errors.New("item " + strconv.Itoa(item) + " failed")
+		}
+		return item, nil
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "item 0 failed")
+	assert.Contains(t, err.Error(), "item 90 failed")
+	assert.Equal(t, 99, numbers[99], "Other items completed.")
+}
+
+// TestParallelForEach tests ParallelForEach().
+func TestParallelForEach(t *testing.T) {
+	// Test every item visited.
+	var sum int64
+	err := ParallelForEach(context.Background(), []int64{1, 2, 3, 4}, ParallelOptions{}, func(_ context.Context, _ int, item int64) error {
+		atomic.AddInt64(&sum, item)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), sum)
+
+	// Test cancellation.
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	err = ParallelForEach(ctx, make([]int, 100), ParallelOptions{Parallelism: 1}, func(taskCtx context.Context, index int, _ int) error {
+		if atomic.AddInt32(&calls, 1) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, atomic.LoadInt32(&calls) < 100, "Stopped after cancellation.")
+}
@@ -0,0 +1,157 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+var (
+	// ErrRateLimitDeadline is returned by RateLimiter.Wait when the wait would outlast the context's deadline.
+	ErrRateLimitDeadline = errors.New("rate limit wait exceeds context deadline")
+)
+
+// RateLimiter is a token bucket that permits calls at a steady rate with bursts, shared safely across goroutines.
+type RateLimiter struct {
+	burst  float64
+	clock  timeUtils.Clock
+	last   time.Time
+	lock   sync.Mutex
+	rate   float64
+	tokens float64
+}
+
+// Reservation defines a permitted call that may need to wait before acting.
+type Reservation struct {
+	limiter   *RateLimiter
+	timeToAct time.Time
+}
+
+// NewRateLimiter creates a rate limiter permitting rate calls per second, with bursts of up to burst calls.
+// The bucket starts full. A rate of zero or less is unlimited.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return NewRateLimiterWith(timeUtils.RealClock, rate, burst)
+}
+
+// NewRateLimiterWith creates a rate limiter that uses clock, permitting rate calls per second with bursts of up to burst calls.
+func NewRateLimiterWith(clock timeUtils.Clock, rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		burst:  float64(burst),
+		clock:  clock,
+		last:   clock.Now(),
+		rate:   rate,
+		tokens: float64(burst),
+	}
+}
+
+// Allow reports whether a call may happen now, consuming a token if so.
+func (l *RateLimiter) Allow() bool {
+	if l.rate <= 0 {
+		return true
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.advance(l.clock.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+
+	return true
+}
+
+// Reserve consumes a token, returning a reservation describing how long the caller must wait before acting.
+func (l *RateLimiter) Reserve() *Reservation {
+	now := l.clock.Now()
+	if l.rate <= 0 {
+		return &Reservation{limiter: l, timeToAct: now}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.advance(now)
+	l.tokens--
+	reservation := &Reservation{limiter: l, timeToAct: now}
+	if l.tokens < 0 {
+		reservation.timeToAct = now.Add(time.Duration(-l.tokens / l.rate * float64(time.Second)))
+	}
+
+	return reservation
+}
+
+// Wait blocks until a call may happen, or the context is done.
+// It returns ErrRateLimitDeadline immediately if the wait would outlast the context's deadline.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	reservation := l.Reserve()
+	delay := reservation.Delay()
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		reservation.Cancel()
+		return ErrRateLimitDeadline
+	}
+	if err := timeUtils.SleepContextWith(ctx, l.clock, delay); err != nil {
+		reservation.Cancel()
+		return err
+	}
+
+	return nil
+}
+
+// Wrap returns a function that waits for the rate limiter before calling method.
+func (l *RateLimiter) Wrap(method func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := l.Wait(ctx); err != nil {
+			return err
+		}
+
+		return method(ctx)
+	}
+}
+
+// advance adds the tokens accrued since the last update.
+func (l *RateLimiter) advance(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// Cancel returns the reservation's token to the limiter if the reservation has not yet become due.
+func (r *Reservation) Cancel() {
+	if r.limiter.rate <= 0 {
+		return
+	}
+
+	r.limiter.lock.Lock()
+	defer r.limiter.lock.Unlock()
+
+	now := r.limiter.clock.Now()
+	if now.Before(r.timeToAct) {
+		r.limiter.advance(now)
+		r.limiter.tokens = math.Min(r.limiter.burst, r.limiter.tokens+1)
+		r.timeToAct = now
+	}
+}
+
+// Delay returns how long the caller must wait before acting.
+func (r *Reservation) Delay() time.Duration {
+	if delay := r.timeToAct.Sub(r.limiter.clock.Now()); delay > 0 {
+		return delay
+	}
+
+	return 0
+}
@@ -0,0 +1,67 @@
+package funcs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRateLimiter tests RateLimiter.
+func TestRateLimiter(t *testing.T) {
+	// Test bursts.
+	limiter := NewRateLimiter(10, 3)
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow(), "Burst exhausted.")
+
+	// Test reservations.
+	reservation := limiter.Reserve()
+	assert.True(t, reservation.Delay() > 50*time.Millisecond && reservation.Delay() <= 100*time.Millisecond)
+	reservation.Cancel()
+	assert.Equal(t, time.Duration(0), reservation.Delay(), "Cancelled reservation.")
+
+	// Test waiting across goroutines.
+	limiter = NewRateLimiter(100, 1)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, limiter.Wait(context.Background()))
+		}()
+	}
+	wg.Wait()
+	assert.True(t, time.Since(start) >= 80*time.Millisecond, "Calls spread at the limited rate.")
+
+	// Test a wait beyond the context deadline.
+	limiter = NewRateLimiter(1, 1)
+	assert.True(t, limiter.Allow())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), ErrRateLimitDeadline)
+	assert.False(t, limiter.Allow(), "Token not consumed by failed wait.")
+
+	// Test an unlimited rate.
+	limiter = NewRateLimiter(0, 1)
+	for i := 0; i < 100; i++ {
+		assert.True(t, limiter.Allow())
+	}
+
+	// Test middleware.
+	limiter = NewRateLimiter(1, 1)
+	calls := 0
+	wrapped := limiter.Wrap(func(context.Context) error {
+		calls++
+		return nil
+	})
+	assert.NoError(t, wrapped(context.Background()))
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, wrapped(ctx))
+	assert.Equal(t, 1, calls)
+}
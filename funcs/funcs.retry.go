@@ -0,0 +1,150 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+// RetryPolicy defines how Retry repeats a method.
+type RetryPolicy struct {
+	AttemptTimeout time.Duration         // Maximum duration of each attempt, applied to the context passed to it; unlimited if zero.
+	Backoff        Backoff               // Delay between attempts; defaults to ExponentialBackoff.
+	CircuitBreaker *CircuitBreaker       // Optional breaker through which each attempt is made.
+	Clock          timeUtils.Clock       // Clock used for backoff delays and elapsed time; defaults to time.RealClock.
+	Classify       func(err error) error // Optionally wraps attempt errors with Permanent, RetryAfter or Throttled before they are inspected.
+	MaxAttempts    int                   // Maximum number of attempts; unlimited if zero.
+	MaxElapsed     time.Duration         // Maximum time from the first attempt until the last may start; unlimited if zero.
+	Observer       RetryObserver         // Optionally receives an event for each attempt, backoff and outcome.
+}
+
+// Retry calls method until it succeeds, reports its error as final, or the policy gives up.
+// Errors are handled as by RetryFunc, with final errors treated as permanent.
+func Retry(ctx context.Context, policy RetryPolicy, method func(ctx context.Context) (bool, error)) error {
+	return RetryFunc(ctx, policy, func(ctx context.Context) error {
+		isFinal, err := method(ctx)
+		if isFinal {
+			return Permanent(err)
+		}
+
+		return err
+	})
+}
+
+// RetryFunc calls method until it succeeds or the policy gives up, returning a *RetryError containing each attempt's error.
+// Errors marked by Permanent and ErrCircuitOpen stop immediately, delays requested by RetryAfter override the backoff, and errors marked by Throttled double the backoff delay.
+// Cancelling the context stops RetryFunc immediately, including during a backoff delay, in which case the context's error is returned wrapping the attempt errors.
+func RetryFunc(ctx context.Context, policy RetryPolicy, method func(ctx context.Context) error) error {
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff{}
+	}
+
+	clock := policy.Clock
+	if clock == nil {
+		clock = timeUtils.RealClock
+	}
+
+	start := clock.Now()
+	notify := retryNotifier(clock, policy.Observer)
+	giveUp := func(attempt int, err error) error {
+		notify(RetryEventGiveUp, attempt, 0, err)
+		return err
+	}
+
+	var delay time.Duration
+	var retryErr *RetryError
+	for attempt := 0; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return giveUp(attempt, retryContextError(ctxErr, retryErr))
+		}
+
+		// Attempt.
+		err := callAttempt(ctx, policy.AttemptTimeout, policy.CircuitBreaker, method)
+		if policy.Classify != nil && err != nil {
+			err = policy.Classify(err)
+		}
+		notify(RetryEventAttempt, attempt+1, 0, err)
+		if err == nil {
+			notify(RetryEventSuccess, attempt+1, 0, nil)
+			return nil
+		}
+		if retryErr == nil {
+			retryErr = &RetryError{}
+		}
+		retryErr.add(err)
+		if IsPermanent(err) || errors.Is(err, ErrCircuitOpen) {
+			return giveUp(attempt+1, retryErr)
+		}
+		if policy.MaxAttempts > 0 && attempt+1 >= policy.MaxAttempts {
+			return giveUp(attempt+1, retryErr)
+		}
+
+		// Wait.
+		delay = backoff.Next(attempt, delay)
+		if IsThrottled(err) {
+			delay *= 2
+		}
+		if retryAfter, ok := RetryAfterDelay(err); ok {
+			delay = retryAfter
+		}
+		if policy.MaxElapsed > 0 && clock.Now().Sub(start)+delay > policy.MaxElapsed {
+			return giveUp(attempt+1, retryErr)
+		}
+		notify(RetryEventBackoff, attempt+1, delay, err)
+		if ctxErr := timeUtils.SleepContextWith(ctx, clock, delay); ctxErr != nil {
+			return giveUp(attempt+1, retryContextError(ctxErr, retryErr))
+		}
+	}
+}
+
+// callAttempt calls method through a circuit breaker and with a context limited to timeout, if specified.
+func callAttempt(ctx context.Context, timeout time.Duration, circuitBreaker *CircuitBreaker, method func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if circuitBreaker != nil {
+		return circuitBreaker.Execute(ctx, method)
+	}
+
+	return method(ctx)
+}
+
+// notifyRetryObserver sends an event to the observer method for its type.
+func notifyRetryObserver(observer RetryObserver, event RetryEvent) {
+	switch event.Type {
+	case RetryEventAttempt:
+		observer.OnAttempt(event)
+	case RetryEventBackoff:
+		observer.OnBackoff(event)
+	case RetryEventGiveUp:
+		observer.OnGiveUp(event)
+	case RetryEventSuccess:
+		observer.OnSuccess(event)
+	}
+}
+
+// retryNotifier returns a function that sends events to observer, if not nil, measuring elapsed time on clock from when it is called.
+func retryNotifier(clock timeUtils.Clock, observer RetryObserver) func(eventType RetryEventType, attempt int, delay time.Duration, err error) {
+	start := clock.Now()
+
+	return func(eventType RetryEventType, attempt int, delay time.Duration, err error) {
+		if observer != nil {
+			notifyRetryObserver(observer, RetryEvent{Attempt: attempt, Delay: delay, Elapsed: clock.Now().Sub(start), Err: err, Type: eventType})
+		}
+	}
+}
+
+// retryContextError returns the error reported when a retry is cancelled.
+func retryContextError(ctxErr error, retryErr *RetryError) error {
+	if retryErr == nil {
+		return ctxErr
+	}
+
+	return fmt.Errorf("%w: %w", ctxErr, retryErr)
+}
@@ -0,0 +1,299 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetry tests Retry().
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{Backoff: ConstantBackoff{Interval: time.Millisecond}}
+
+	// Test success after failures.
+	attempts := 0
+	err := Retry(ctx, policy, func(context.Context) (bool, error) {
+		attempts++
+		if attempts < 3 {
+			return false, errors.New("sample error")
+		}
+		return false, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// Test a final error.
+	attempts = 0
+	err = Retry(ctx, policy, func(context.Context) (bool, error) {
+		attempts++
+		return true, errors.New("final error")
+	})
+	assert.EqualError(t, err, "final error")
+	assert.Equal(t, 1, attempts)
+
+	// Test maximum attempts.
+	attempts = 0
+	policy.MaxAttempts = 4
+	err = Retry(ctx, policy, func(context.Context) (bool, error) {
+		attempts++
+		return false, errors.New("sample error")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 4, attempts)
+
+	// Test maximum elapsed time.
+	attempts = 0
+	start := time.Now()
+	err = Retry(ctx, RetryPolicy{Backoff: ConstantBackoff{Interval: 20 * time.Millisecond}, MaxElapsed: 100 * time.Millisecond}, func(context.Context) (bool, error) {
+		attempts++
+		return false, errors.New("sample error")
+	})
+	assert.Error(t, err)
+	assert.True(t, attempts >= 2 && attempts <= 6, "Attempts within elapsed time.")
+	assert.True(t, time.Since(start) < 200*time.Millisecond, "Gave up near elapsed time.")
+
+	// Test per-attempt timeouts.
+	attempts = 0
+	err = Retry(ctx, RetryPolicy{AttemptTimeout: 10 * time.Millisecond, Backoff: ConstantBackoff{Interval: time.Millisecond}, MaxAttempts: 3}, func(attemptCtx context.Context) (bool, error) {
+		attempts++
+		<-attemptCtx.Done()
+		return false, attemptCtx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 3, attempts, "Attempt timeouts retried.")
+}
+
+// TestRetryFunc tests RetryFunc().
+func TestRetryFunc(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{Backoff: ConstantBackoff{Interval: time.Millisecond}, MaxAttempts: 5}
+
+	// Test permanent errors.
+	attempts := 0
+	err := RetryFunc(ctx, policy, func(context.Context) error {
+		attempts++
+		if attempts == 2 {
+			return Permanent(errors.New("permanent error"))
+		}
+		return errors.New("temporary error")
+	})
+	assert.Equal(t, 2, attempts)
+	var retryErr *RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, 2, retryErr.Attempts)
+	assert.EqualError(t, retryErr.Errors[0], "temporary error")
+	assert.EqualError(t, retryErr.Last(), "permanent error")
+
+	// Test aggregated errors when attempts are exhausted.
+	sentinel := errors.New("sentinel")
+	attempts = 0
+	err = RetryFunc(ctx, policy, func(context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return sentinel
+		}
+		return errors.New("temporary error")
+	})
+	assert.ErrorIs(t, err, sentinel, "Earlier errors retained.")
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Len(t, retryErr.Errors, 5)
+
+	// Test a retry-after hint overriding the backoff.
+	attempts = 0
+	start := time.Now()
+	err = RetryFunc(ctx, RetryPolicy{Backoff: ConstantBackoff{Interval: time.Minute}}, func(context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return RetryAfter(errors.New("busy"), 10*time.Millisecond)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < time.Second, "Retry-after hint used.")
+
+	// Test a retry-after hint beyond the maximum elapsed time.
+	attempts = 0
+	err = RetryFunc(ctx, RetryPolicy{MaxElapsed: time.Second}, func(context.Context) error {
+		attempts++
+		return RetryAfter(errors.New("busy"), time.Hour)
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// Test a classifier.
+	notFound := errors.New("not found")
+	attempts = 0
+	policy.Classify = func(err error) error {
+		if errors.Is(err, notFound) {
+			return Permanent(err)
+		}
+		return err
+	}
+	err = RetryFunc(ctx, policy, func(context.Context) error {
+		attempts++
+		return fmt.Errorf("lookup: %w", notFound)
+	})
+	assert.ErrorIs(t, err, notFound)
+	assert.Equal(t, 1, attempts, "Classified as permanent.")
+
+	// Test throttled errors doubling the delay.
+	attempts = 0
+	start = time.Now()
+	err = RetryFunc(ctx, RetryPolicy{Backoff: ConstantBackoff{Interval: 20 * time.Millisecond}, MaxAttempts: 2}, func(context.Context) error {
+		attempts++
+		return Throttled(errors.New("slow down"))
+	})
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) >= 40*time.Millisecond, "Throttled delay doubled.")
+}
+
+// TestRetryCancel tests cancelling Retry().
+func TestRetryCancel(t *testing.T) {
+	// Test cancellation during a backoff delay.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	attempts := 0
+	err := Retry(ctx, RetryPolicy{Backoff: ConstantBackoff{Interval: time.Minute}}, func(context.Context) (bool, error) {
+		attempts++
+		return false, errors.New("sample error")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), "sample error", "Last error included.")
+	assert.Equal(t, 1, attempts)
+	assert.True(t, time.Since(start) < time.Second, "Cancelled mid-sleep.")
+
+	// Test an already cancelled context.
+	attempts = 0
+	err = Retry(ctx, RetryPolicy{}, func(context.Context) (bool, error) {
+		attempts++
+		return false, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, attempts)
+}
+
+// TestRetryClock tests Retry() on a fake clock.
+func TestRetryClock(t *testing.T) {
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	stats := &RetryStats{}
+	policy := RetryPolicy{
+		Backoff:    ExponentialBackoff{Initial: time.Minute, Jitter: 0.5, JitterSource: timeUtils.NewJitterSource(1)},
+		Clock:      clock,
+		MaxElapsed: time.Hour,
+		Observer:   stats,
+	}
+	done := make(chan error)
+	go func() {
+		done <- Retry(context.Background(), policy, func(context.Context) (bool, error) {
+			return false, errors.New("sample error")
+		})
+	}()
+
+	// Advance past each backoff until the elapsed limit is reached.
+	for {
+		select {
+		case err := <-done:
+			assert.Error(t, err)
+			counts := stats.Counts()
+			assert.True(t, counts.Attempts >= 3, "Attempts within an hour of fake time.")
+			assert.Equal(t, 1, counts.GiveUps)
+			events := stats.Events()
+			assert.True(t, events[len(events)-1].Elapsed >= time.Hour, "Elapsed fake time.")
+			return
+		default:
+		}
+		if clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		clock.Advance(10 * time.Minute)
+	}
+}
+
+// TestRetryNWith tests RetryNWith().
+func TestRetryNWith(t *testing.T) {
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	attempts := 0
+	stats := &RetryStats{}
+	done := make(chan error)
+	go func() {
+		done <- RetryNWith(clock, timeUtils.NewJitterSource(1), stats, func() (bool, error) {
+			attempts++
+			if attempts < 3 {
+				return false, errors.New("sample error")
+			}
+			return true, nil
+		}, 5)
+	}()
+
+	// Advance past each backoff.
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}
+	assert.NoError(t, <-done)
+	assert.Equal(t, 3, attempts)
+	counts := stats.Counts()
+	assert.Equal(t, 3, counts.Attempts)
+	assert.Equal(t, 2, counts.Backoffs)
+	assert.Equal(t, 2, counts.Failures)
+	assert.Equal(t, 1, counts.Successes)
+	assert.True(t, counts.TotalBackoff >= 750*time.Millisecond, "Backoff delays observed.")
+
+	// Test giving up.
+	stats.Reset()
+	go func() {
+		done <- RetryNWith(clock, timeUtils.NewJitterSource(1), stats, func() (bool, error) {
+			return true, errors.New("final error")
+		}, 5)
+	}()
+	assert.Error(t, <-done)
+	assert.Equal(t, 1, stats.Counts().GiveUps)
+
+	// Test exhausting retries without a backoff after the last attempt.
+	stats.Reset()
+	go func() {
+		done <- RetryNWith(clock, timeUtils.NewJitterSource(1), stats, func() (bool, error) {
+			return false, errors.New("sample error")
+		}, 2)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.Error(t, <-done)
+	assert.Equal(t, 0, clock.Waiters(), "No sleep after the last attempt.")
+	counts = stats.Counts()
+	assert.Equal(t, 2, counts.Attempts)
+	assert.Equal(t, 1, counts.Backoffs)
+	assert.Equal(t, 1, counts.GiveUps)
+	events := stats.Events()
+	assert.Equal(t, RetryEventGiveUp, events[len(events)-1].Type)
+	assert.Equal(t, RetryEventAttempt, events[len(events)-2].Type, "No trailing backoff.")
+
+	// Test RetryUnlimitedWith().
+	attempts = 0
+	stats.Reset()
+	go func() {
+		done <- RetryUnlimitedWith(clock, stats, func() (bool, error) {
+			attempts++
+			if attempts < 4 {
+				return false, errors.New("sample error")
+			}
+			return false, nil
+		})
+	}()
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+	assert.NoError(t, <-done)
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, 4, stats.Counts().Attempts)
+	assert.Equal(t, 3*time.Second, stats.Counts().TotalBackoff)
+}
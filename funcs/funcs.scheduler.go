@@ -0,0 +1,147 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+)
+
+// Schedule calculates when a job next runs.
+type Schedule interface {
+	// Next returns the first run time after the given time, or the zero time if there is none.
+	Next(after time.Time) time.Time
+}
+
+// IntervalSchedule runs at multiples of an interval since the Unix epoch, so that runs are aligned across restarts and processes.
+type IntervalSchedule struct {
+	Interval time.Duration // Time between runs.
+}
+
+// Job defines a scheduled job.
+type Job struct {
+	Jitter   time.Duration                   // Maximum random delay added to each run, to spread load.
+	Name     string                          // Unique job name.
+	Run      func(ctx context.Context) error // Job function, called with the scheduler's context.
+	Schedule Schedule                        // When the job runs.
+}
+
+// Scheduler runs jobs on schedules. A job's next run is calculated when its previous run finishes, so runs of the same job never overlap.
+type Scheduler struct {
+	Clock        timeUtils.Clock             // Clock used to wait for runs; defaults to time.RealClock.
+	JitterSource timeUtils.JitterSource      // Random source for job jitter; defaults to time.DefaultJitterSource.
+	OnError      func(job string, err error) // Called when a job returns an error.
+
+	ctx  context.Context
+	jobs map[string]Job
+	lock sync.Mutex
+	wg   sync.WaitGroup
+}
+
+// Cron returns a schedule for a cron expression, as parsed by time.ParseCron.
+func Cron(expression string) (Schedule, error) {
+	return timeUtils.ParseCron(expression)
+}
+
+// Every returns a schedule that runs at aligned multiples of interval.
+func Every(interval time.Duration) Schedule {
+	return IntervalSchedule{Interval: interval}
+}
+
+// NewScheduler creates a scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		jobs: map[string]Job{},
+	}
+}
+
+// Next returns the next multiple of the interval after the given time.
+func (s IntervalSchedule) Next(after time.Time) time.Time {
+	if s.Interval <= 0 {
+		return time.Time{}
+	}
+
+	nanoseconds := after.UnixNano()
+	return time.Unix(0, nanoseconds-nanoseconds%int64(s.Interval)+int64(s.Interval)).In(after.Location())
+}
+
+// Add adds a job. Jobs added while the scheduler is running start immediately.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil || job.Schedule == nil {
+		return errors.New("job name, function and schedule are required")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.jobs == nil {
+		s.jobs = map[string]Job{}
+	}
+	if _, ok := s.jobs[job.Name]; ok {
+		return errors.New("job already exists: " + job.Name)
+	}
+	s.jobs[job.Name] = job
+	if s.ctx != nil {
+		s.start(s.ctx, job)
+	}
+
+	return nil
+}
+
+// Run runs jobs until the context is cancelled, then waits for running jobs to return.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.lock.Lock()
+	if s.ctx != nil {
+		s.lock.Unlock()
+		return errors.New("scheduler already running")
+	}
+	s.ctx = ctx
+	for _, job := range s.jobs {
+		s.start(ctx, job)
+	}
+	s.lock.Unlock()
+
+	// Stop starting jobs, then wait for running jobs.
+	<-ctx.Done()
+	s.lock.Lock()
+	s.ctx = nil
+	s.lock.Unlock()
+	s.wg.Wait()
+
+	return ctx.Err()
+}
+
+// start runs a job's loop on its own goroutine.
+func (s *Scheduler) start(ctx context.Context, job Job) {
+	clock := s.Clock
+	if clock == nil {
+		clock = timeUtils.RealClock
+	}
+	jitterSource := jitterSourceOrDefault(s.JitterSource)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			// Wait for the next run.
+			now := clock.Now()
+			next := job.Schedule.Next(now)
+			if next.IsZero() {
+				return
+			}
+			if job.Jitter > 0 {
+				next = next.Add(time.Duration(jitterSource.Int63n(int64(job.Jitter))))
+			}
+			if err := timeUtils.SleepContextWith(ctx, clock, next.Sub(now)); err != nil {
+				return
+			}
+
+			// Run.
+			if err := job.Run(ctx); err != nil && s.OnError != nil {
+				s.OnError(job.Name, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,115 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timeUtils "github.com/bertjohnson/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIntervalSchedule tests IntervalSchedule.
+func TestIntervalSchedule(t *testing.T) {
+	schedule := Every(15 * time.Minute)
+	start := time.Date(2024, time.January, 1, 12, 7, 30, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, time.January, 1, 12, 15, 0, 0, time.UTC), schedule.Next(start))
+	assert.Equal(t, time.Date(2024, time.January, 1, 12, 30, 0, 0, time.UTC), schedule.Next(schedule.Next(start)))
+
+	// Test a cron schedule.
+	schedule, err := Cron("0 * * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.January, 1, 13, 0, 0, 0, time.UTC), schedule.Next(start))
+	_, err = Cron("invalid")
+	assert.Error(t, err)
+}
+
+// TestScheduler tests Scheduler.
+func TestScheduler(t *testing.T) {
+	scheduler := NewScheduler()
+	var lock sync.Mutex
+	errs := []string{}
+	scheduler.OnError = func(job string, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		errs = append(errs, job+": "+err.Error())
+	}
+
+	// Add a slow job that must not overlap.
+	var running int32
+	var overlapped int32
+	var slowRuns int32
+	assert.NoError(t, scheduler.Add(Job{Name: "slow", Schedule: Every(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		defer atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&slowRuns, 1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}}))
+
+	// Add a failing job with jitter.
+	assert.NoError(t, scheduler.Add(Job{Jitter: 5 * time.Millisecond, Name: "failing", Schedule: Every(10 * time.Millisecond), Run: func(ctx context.Context) error {
+		return errors.New("sample error")
+	}}))
+	assert.Error(t, scheduler.Add(Job{Name: "failing", Schedule: Every(time.Second), Run: func(context.Context) error { return nil }}), "Duplicate name.")
+	assert.Error(t, scheduler.Add(Job{Name: "incomplete"}), "Missing function.")
+
+	// Run and shut down, waiting for running jobs.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- scheduler.Run(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Add a job while running.
+	var lateRuns int32
+	assert.NoError(t, scheduler.Add(Job{Name: "late", Schedule: Every(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		atomic.AddInt32(&lateRuns, 1)
+		return nil
+	}}))
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&running), "Running jobs finished.")
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&overlapped), "No overlapping runs.")
+	assert.True(t, atomic.LoadInt32(&slowRuns) >= 2)
+	assert.True(t, atomic.LoadInt32(&lateRuns) >= 1, "Job added while running.")
+	lock.Lock()
+	assert.NotEmpty(t, errs)
+	assert.Equal(t, "failing: sample error", errs[0])
+	lock.Unlock()
+}
+
+// TestSchedulerClock tests Scheduler on a fake clock.
+func TestSchedulerClock(t *testing.T) {
+	clock := timeUtils.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 30, 0, time.UTC))
+	scheduler := NewScheduler()
+	scheduler.Clock = clock
+	scheduler.JitterSource = timeUtils.NewJitterSource(1)
+	runs := make(chan time.Time, 10)
+	assert.NoError(t, scheduler.Add(Job{Name: "minutely", Schedule: Every(time.Minute), Run: func(ctx context.Context) error {
+		runs <- clock.Now()
+		return nil
+	}}))
+
+	// Run twice on minute boundaries.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- scheduler.Run(ctx)
+	}()
+	for i := 1; i <= 2; i++ {
+		clock.BlockUntil(1)
+		clock.Set(time.Date(2024, time.January, 1, 0, i, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2024, time.January, 1, 0, i, 0, 0, time.UTC), <-runs)
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,65 @@
+package funcs
+
+import (
+	"context"
+	"sync"
+
+	hashUtils "github.com/bertjohnson/util/hash"
+)
+
+// Group coalesces concurrent calls with the same key into a single execution. The zero value is ready to use.
+type Group[V any] struct {
+	calls map[uint64]*singleflightCall[V]
+	lock  sync.Mutex
+}
+
+// singleflightCall defines an execution shared by callers with the same key.
+type singleflightCall[V any] struct {
+	done    chan struct{}
+	err     error
+	waiters int
+	value   V
+}
+
+// Do calls method unless a call with an equal key is already in progress, in which case it waits for that call's result.
+// Keys may be any value supported by hash.HighwayHashUInt64, including structs. shared reports whether the result was delivered to more than one caller.
+// If ctx is done before the result is ready, Do returns the context's error while the call continues for any other callers.
+func (g *Group[V]) Do(ctx context.Context, key interface{}, method func() (V, error)) (value V, shared bool, err error) {
+	hash, err := hashUtils.HighwayHashUInt64(ctx, key)
+	if err != nil {
+		return value, false, err
+	}
+
+	return g.do(ctx, hash, method)
+}
+
+// do calls method unless a call with the same key hash is already in progress.
+func (g *Group[V]) do(ctx context.Context, hash uint64, method func() (V, error)) (V, bool, error) {
+	g.lock.Lock()
+	if g.calls == nil {
+		g.calls = map[uint64]*singleflightCall[V]{}
+	}
+	call, ok := g.calls[hash]
+	if ok {
+		call.waiters++
+	} else {
+		call = &singleflightCall[V]{done: make(chan struct{})}
+		g.calls[hash] = call
+		go func() {
+			call.value, call.err = method()
+			g.lock.Lock()
+			delete(g.calls, hash)
+			g.lock.Unlock()
+			close(call.done)
+		}()
+	}
+	g.lock.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.waiters > 0, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ok, ctx.Err()
+	}
+}
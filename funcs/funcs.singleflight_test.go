@@ -0,0 +1,63 @@
+package funcs
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGroup tests Group.
+func TestGroup(t *testing.T) {
+	type request struct {
+		Account string
+		Scopes  []string
+	}
+	var group Group[string]
+	var calls int32
+	release := make(chan struct{})
+	method := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "token", nil
+	}
+
+	// Test coalescing concurrent calls with equal struct keys.
+	var wg sync.WaitGroup
+	var sharedCount int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, shared, err := group.Do(context.Background(), request{Account: "a", Scopes: []string{"read"}}, method)
+			assert.NoError(t, err)
+			assert.Equal(t, "token", value)
+			if shared {
+				atomic.AddInt32(&sharedCount, 1)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Calls coalesced.")
+	assert.Equal(t, int32(10), atomic.LoadInt32(&sharedCount))
+
+	// Test different keys running separately.
+	_, shared, err := group.Do(context.Background(), request{Account: "b"}, method)
+	assert.NoError(t, err)
+	assert.False(t, shared)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Test a waiter abandoning a slow call.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = group.Do(ctx, "slow", func() (string, error) {
+		time.Sleep(100 * time.Millisecond)
+		return "slow", nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -1,13 +1,14 @@
 module github.com/bertjohnson/util
 
-go 1.20
+go 1.21
 
 require (
-	github.com/araddon/dateparse v0.0.0-20210429162001-6b43995a97de
 	github.com/json-iterator/go v1.1.12
 	github.com/minio/highwayhash v1.0.2
 	github.com/mitchellh/hashstructure v1.1.0
 	github.com/shengdoushi/base58 v1.0.0
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/crypto v0.17.0
 )
 
 require (
@@ -15,7 +16,6 @@ require (
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.8.4 // indirect
-	golang.org/x/sys v0.9.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -8,8 +8,8 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/araddon/dateparse"
 	utilstrings "github.com/bertjohnson/util/strings"
+	timeUtils "github.com/bertjohnson/util/time"
 )
 
 var (
@@ -210,7 +210,7 @@ runeLoop:
 
 	// Check for string dates/times.
 	if len(originalDataString) < 32 {
-		if timeVal, err := dateparse.ParseAny(originalDataString); err == nil {
+		if timeVal, err := timeUtils.Parse(originalDataString); err == nil {
 			if timeVal.Year() != 0 {
 				return reflect.ValueOf(timeVal.UnixNano() / 1000000)
 			}
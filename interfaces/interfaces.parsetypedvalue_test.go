@@ -81,6 +81,11 @@ func TestParseTypedValue(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedValue.Interface(), val.Interface())
 
+	// Date.
+	val = ParseTypedValue("04/27/2018")
+	expectedValue = reflect.ValueOf(time.Date(2018, time.April, 27, 0, 0, 0, 0, time.UTC).UnixNano() / 1000000)
+	assert.Equal(t, expectedValue.Interface(), val.Interface())
+
 	// Empty.
 	val = ParseTypedValue("")
 	expectedValue = (reflect.Value{})
@@ -0,0 +1,278 @@
+package time
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultJitterSource draws jitter from the math/rand global source.
+	DefaultJitterSource JitterSource = globalJitterSource{}
+
+	// RealClock is a clock backed by the time package.
+	RealClock Clock = realClock{}
+)
+
+// Clock provides the current time and timers, so that time-dependent code can be tested with FakeClock.
+type Clock interface {
+	After(duration time.Duration) <-chan time.Time // Returns a channel that receives the time after duration.
+	NewTimer(duration time.Duration) Timer         // Returns a timer that fires after duration.
+	Now() time.Time                                // Returns the current time.
+	Sleep(duration time.Duration)                  // Blocks for duration.
+}
+
+// JitterSource provides random numbers for jitter. *rand.Rand satisfies it, but is not safe for concurrent use; see NewJitterSource.
+type JitterSource interface {
+	Float64() float64     // Returns a number in [0.0, 1.0).
+	Int63n(n int64) int64 // Returns a number in [0, n).
+}
+
+// Timer is a single-event timer created by a Clock.
+type Timer interface {
+	C() <-chan time.Time               // Returns the channel on which the time is delivered.
+	Reset(duration time.Duration) bool // Changes the timer to fire after duration, reporting whether it had been active.
+	Stop() bool                        // Prevents the timer from firing, reporting whether it had been active.
+}
+
+// FakeClock is a Clock whose time only changes when it is advanced, for deterministic tests. It is safe for concurrent use.
+type FakeClock struct {
+	changed *sync.Cond
+	lock    sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// fakeTimer defines a timer created by a FakeClock.
+type fakeTimer struct {
+	c     chan time.Time
+	clock *FakeClock
+	when  time.Time
+}
+
+// globalJitterSource draws from the math/rand global source.
+type globalJitterSource struct{}
+
+// lockedJitterSource serializes access to a seeded source.
+type lockedJitterSource struct {
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+// realClock is backed by the time package.
+type realClock struct{}
+
+// realTimer wraps a time.Timer.
+type realTimer struct {
+	timer *time.Timer
+}
+
+// NewFakeClock creates a fake clock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	clock := &FakeClock{now: now}
+	clock.changed = sync.NewCond(&clock.lock)
+
+	return clock
+}
+
+// NewJitterSource creates a jitter source seeded with seed, producing the same sequence for the same seed. It is safe for concurrent use.
+func NewJitterSource(seed int64) JitterSource {
+	return &lockedJitterSource{rand: rand.New(rand.NewSource(seed))} // nolint: gosec
+}
+
+// Advance moves the clock forward, firing any timers that become due in order.
+func (c *FakeClock) Advance(duration time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setLocked(c.now.Add(duration))
+}
+
+// After returns a channel that receives the time once the clock has advanced by duration.
+func (c *FakeClock) After(duration time.Duration) <-chan time.Time {
+	return c.NewTimer(duration).C()
+}
+
+// BlockUntil waits until at least count timers, including sleepers, are waiting on the clock.
+func (c *FakeClock) BlockUntil(count int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for len(c.timers) < count {
+		c.changed.Wait()
+	}
+}
+
+// NewTimer returns a timer that fires once the clock has advanced by duration.
+func (c *FakeClock) NewTimer(duration time.Duration) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	timer := &fakeTimer{c: make(chan time.Time, 1), clock: c}
+	c.scheduleLocked(timer, duration)
+
+	return timer
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// Set moves the clock to a time, firing any timers that become due.
+func (c *FakeClock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setLocked(now)
+}
+
+// Sleep blocks until the clock has advanced by duration.
+func (c *FakeClock) Sleep(duration time.Duration) {
+	<-c.After(duration)
+}
+
+// Waiters returns the number of timers, including sleepers, waiting on the clock.
+func (c *FakeClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.timers)
+}
+
+// removeLocked removes a timer, reporting whether it was waiting.
+func (c *FakeClock) removeLocked(timer *fakeTimer) bool {
+	for i, waiting := range c.timers {
+		if waiting == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// scheduleLocked sets a timer to fire after duration, firing it immediately if duration is not positive.
+func (c *FakeClock) scheduleLocked(timer *fakeTimer, duration time.Duration) {
+	timer.when = c.now.Add(duration)
+	if duration <= 0 {
+		timer.fire(c.now)
+		return
+	}
+
+	c.timers = append(c.timers, timer)
+	c.changed.Broadcast()
+}
+
+// setLocked moves the clock to a time, firing due timers.
+func (c *FakeClock) setLocked(now time.Time) {
+	c.now = now
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+	for len(c.timers) > 0 && !c.timers[0].when.After(now) {
+		c.timers[0].fire(now)
+		c.timers = c.timers[1:]
+	}
+	c.changed.Broadcast()
+}
+
+// C returns the channel on which the time is delivered.
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Reset changes the timer to fire after duration.
+func (t *fakeTimer) Reset(duration time.Duration) bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	active := t.clock.removeLocked(t)
+	t.clock.scheduleLocked(t, duration)
+
+	return active
+}
+
+// Stop prevents the timer from firing.
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	active := t.clock.removeLocked(t)
+	t.clock.changed.Broadcast()
+
+	return active
+}
+
+// fire delivers the time without blocking.
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+// Float64 returns a number in [0.0, 1.0) from the global source.
+func (globalJitterSource) Float64() float64 {
+	return rand.Float64() // nolint: gosec
+}
+
+// Int63n returns a number in [0, n) from the global source.
+func (globalJitterSource) Int63n(n int64) int64 {
+	return rand.Int63n(n) // nolint: gosec
+}
+
+// Float64 returns a number in [0.0, 1.0).
+func (s *lockedJitterSource) Float64() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.rand.Float64()
+}
+
+// Int63n returns a number in [0, n).
+func (s *lockedJitterSource) Int63n(n int64) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.rand.Int63n(n)
+}
+
+// After returns a channel that receives the time after duration.
+func (realClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+// NewTimer returns a timer that fires after duration.
+func (realClock) NewTimer(duration time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(duration)}
+}
+
+// Now returns the current time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep blocks for duration.
+func (realClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// C returns the channel on which the time is delivered.
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Reset changes the timer to fire after duration.
+func (t realTimer) Reset(duration time.Duration) bool {
+	return t.timer.Reset(duration)
+}
+
+// Stop prevents the timer from firing.
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
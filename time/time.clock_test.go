@@ -0,0 +1,115 @@
+package time
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFakeClock tests FakeClock.
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now(), "Initial time.")
+
+	// Test timers firing in order.
+	first := clock.NewTimer(time.Second)
+	second := clock.After(2 * time.Second)
+	assert.Equal(t, 2, clock.Waiters(), "Waiting timers.")
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-first.C():
+		t.Fatal("Timer fired early.")
+	default:
+	}
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(1500*time.Millisecond), <-first.C(), "First timer.")
+	assert.Equal(t, 1, clock.Waiters(), "Waiting timers.")
+	clock.Set(start.Add(time.Minute))
+	assert.Equal(t, start.Add(time.Minute), <-second, "Second timer.")
+	assert.Equal(t, 0, clock.Waiters(), "Waiting timers.")
+
+	// Test stopping and resetting.
+	timer := clock.NewTimer(time.Second)
+	assert.True(t, timer.Stop(), "Stopped active timer.")
+	assert.False(t, timer.Stop(), "Stopped inactive timer.")
+	assert.False(t, timer.Reset(time.Second), "Reset inactive timer.")
+	assert.True(t, timer.Reset(2*time.Second), "Reset active timer.")
+	clock.Advance(time.Second)
+	assert.Equal(t, 1, clock.Waiters(), "Reset timer waiting.")
+	clock.Advance(time.Second)
+	<-timer.C()
+
+	// Test a non-positive duration.
+	<-clock.After(0)
+
+	// Test a blocked sleeper.
+	done := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Hour)
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-done
+}
+
+// TestNewJitterSource tests NewJitterSource().
+func TestNewJitterSource(t *testing.T) {
+	first := NewJitterSource(42)
+	second := NewJitterSource(42)
+	for i := 0; i < 10; i++ {
+		value := first.Int63n(1000)
+		assert.Equal(t, value, second.Int63n(1000), "Same sequence.")
+		assert.True(t, value >= 0 && value < 1000, "Value in range.")
+		fraction := first.Float64()
+		assert.Equal(t, fraction, second.Float64(), "Same sequence.")
+		assert.True(t, fraction >= 0 && fraction < 1, "Fraction in range.")
+	}
+}
+
+// TestSleepWith tests SleepContextWith(), SleepIncrementalWith() and SleepUntilWith().
+func TestSleepWith(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	// Test a context sleep.
+	result := make(chan error, 1)
+	go func() {
+		result <- SleepContextWith(context.Background(), clock, time.Minute)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	assert.NoError(t, <-result, "Complete sleep.")
+
+	// Test a cancelled context sleep.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		result <- SleepContextWith(ctx, clock, time.Minute)
+	}()
+	clock.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled, "Cancelled sleep.")
+	assert.Equal(t, 0, clock.Waiters(), "Timer stopped.")
+
+	// Test an incremental sleep.
+	done := make(chan struct{})
+	go func() {
+		SleepIncrementalWith(clock, NewJitterSource(1), 2)
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-done
+
+	// Test sleeping until a time.
+	go func() {
+		SleepUntilWith(clock, clock.Now().Add(time.Hour))
+		close(result)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-result
+}
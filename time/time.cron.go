@@ -0,0 +1,513 @@
+package time
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// cronSearchYears is how far Next and Previous search for a matching time when the year is not restricted.
+	cronSearchYears = 5
+)
+
+// cronField defines the range and names of a cron field.
+type cronField struct {
+	max     int
+	min     int
+	name    string
+	names   map[string]int
+	openMax int // Largest value of "*" and open-ended steps, if less than max.
+}
+
+// Cron fields.
+var (
+	cronSecond  = cronField{name: "second", min: 0, max: 59}
+	cronMinute  = cronField{name: "minute", min: 0, max: 59}
+	cronHour    = cronField{name: "hour", min: 0, max: 23}
+	cronDay     = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth   = cronField{name: "month", min: 1, max: 12, names: map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}}
+	cronWeekday = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}, openMax: 6}
+	cronYear    = cronField{name: "year", min: 1970, max: 2199}
+)
+
+var (
+	// cronMacros are the expressions that macros stand for.
+	cronMacros = map[string]string{
+		"@annually": "0 0 1 1 *",
+		"@daily":    "0 0 * * *",
+		"@hourly":   "0 * * * *",
+		"@midnight": "0 0 * * *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@yearly":   "0 0 1 1 *",
+	}
+)
+
+// CronSchedule is a parsed cron expression.
+type CronSchedule struct {
+	days            uint64
+	dayStar         bool
+	hours           uint64
+	lastDayOffsets  uint64
+	lastWeekday     bool
+	lastWeekdays    uint64
+	location        *time.Location
+	minutes         uint64
+	months          uint64
+	nearestWeekdays uint64
+	nthWeekdays     [7]uint8
+	seconds         uint64
+	weekdays        uint64
+	weekdayStar     bool
+	years           map[int]bool
+}
+
+// ParseCron parses a cron expression. Five fields are minute, hour, day of month, month and day of week; six fields add a leading second, and seven fields add a trailing year.
+// Fields accept "*", values, ranges ("1-5"), lists ("1,15") and steps ("*/10"). Months and days of week accept three-letter English names, and Sunday is 0 or 7.
+// The day of month also accepts "L" for the last day, "L-3" for three days before it, "15W" for the weekday nearest the 15th and "LW" for the last weekday.
+// The day of week also accepts "5L" for the last Friday of the month and "5#3" for the third.
+// As in Vixie cron, if both day fields are restricted, a time matches if either does; a field starting with "*", such as "*/2", is not restricted.
+// The macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are accepted, and a leading "CRON_TZ=Europe/Berlin" or "TZ=Europe/Berlin" sets the time zone.
+func ParseCron(expression string) (*CronSchedule, error) {
+	schedule := &CronSchedule{}
+	fields := strings.Fields(expression)
+
+	// Parse the time zone.
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		name := fields[0][strings.Index(fields[0], "=")+1:]
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, errors.New("invalid cron time zone: " + name)
+		}
+		schedule.location = location
+		fields = fields[1:]
+	}
+
+	// Expand macros.
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, errors.New("unknown cron macro: " + fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+	switch len(fields) {
+	case 5:
+		fields = append(append([]string{"0"}, fields...), "*")
+	case 6:
+		fields = append(fields, "*")
+	case 7:
+	default:
+		return nil, errors.New("cron expression must have 5, 6 or 7 fields: " + expression)
+	}
+
+	schedule.dayStar = strings.HasPrefix(fields[3], "*") || fields[3] == "?"
+	schedule.weekdayStar = strings.HasPrefix(fields[5], "*") || fields[5] == "?"
+	var err error
+	if schedule.seconds, err = parseCronField(fields[0], cronSecond); err != nil {
+		return nil, err
+	}
+	if schedule.minutes, err = parseCronField(fields[1], cronMinute); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseCronField(fields[2], cronHour); err != nil {
+		return nil, err
+	}
+	if err = schedule.parseDays(fields[3]); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseCronField(fields[4], cronMonth); err != nil {
+		return nil, err
+	}
+	if err = schedule.parseWeekdays(fields[5]); err != nil {
+		return nil, err
+	}
+	if fields[6] != "*" && fields[6] != "?" {
+		schedule.years = map[int]bool{}
+		if err = forEachCronValue(fields[6], cronYear, func(value int) {
+			schedule.years[value] = true
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedule, nil
+}
+
+// SleepUntilNext sleeps until the next time matching a cron expression, or until the context is done, returning the time slept until.
+func SleepUntilNext(ctx context.Context, expression string) (time.Time, error) {
+	return SleepUntilNextWith(ctx, RealClock, expression)
+}
+
+// SleepUntilNextWith sleeps on clock until the next time matching a cron expression, or until the context is done, returning the time slept until.
+func SleepUntilNextWith(ctx context.Context, clock Clock, expression string) (time.Time, error) {
+	schedule, err := ParseCron(expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	now := clock.Now()
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return time.Time{}, errors.New("cron expression has no upcoming time: " + expression)
+	}
+
+	return next, SleepContextWith(ctx, clock, next.Sub(now))
+}
+
+// Location returns the schedule's time zone, or nil if times are matched in the location of the time given to Next and Previous.
+func (c *CronSchedule) Location() *time.Location {
+	return c.location
+}
+
+// Next returns the first time after the given time that matches the schedule, in the schedule's time zone or otherwise the given time's location.
+// Wall clock times skipped by a daylight saving change match when the change happens, and wall clock times repeated by one match only once, at their first occurrence.
+// It returns the zero time if no match exists within five years, or within the years of a seven-field expression.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	location := c.locationFor(after)
+	after = after.In(location)
+	wall := cronWall(after).Add(time.Second)
+	limit := wall.Year() + cronSearchYears
+	if c.years != nil {
+		limit = cronYear.max
+	}
+
+	for {
+		if wall = c.nextWall(wall, limit); wall.IsZero() {
+			return time.Time{}
+		}
+		if next := cronInstant(wall, location); next.After(after) {
+			return next
+		}
+		wall = wall.Add(time.Second)
+	}
+}
+
+// Previous returns the last time before the given time that matches the schedule, with the same time zone and daylight saving handling as Next.
+// It returns the zero time if no match exists within five years, or within the years of a seven-field expression.
+func (c *CronSchedule) Previous(before time.Time) time.Time {
+	location := c.locationFor(before)
+	before = before.In(location)
+	wall := cronWall(before)
+	if before.Nanosecond() == 0 {
+		wall = wall.Add(-time.Second)
+	}
+
+	// Times repeated by a daylight saving change match at their first occurrence, so during the second occurrence, search from the end of the first.
+	if start, _ := before.ZoneBounds(); !start.IsZero() {
+		_, offset := before.Zone()
+		_, previousOffset := start.Add(-time.Nanosecond).Zone()
+		if previousOffset > offset && before.Before(start.Add(time.Duration(previousOffset-offset)*time.Second)) {
+			wall = cronWall(start.Add(-time.Second))
+		}
+	}
+	limit := wall.Year() - cronSearchYears
+	if c.years != nil {
+		limit = cronYear.min
+	}
+
+	for {
+		if wall = c.previousWall(wall, limit); wall.IsZero() {
+			return time.Time{}
+		}
+		if previous := cronInstant(wall, location); previous.Before(before) {
+			return previous
+		}
+		wall = wall.Add(-time.Second)
+	}
+}
+
+// locationFor returns the location in which to match a time.
+func (c *CronSchedule) locationFor(t time.Time) *time.Location {
+	if c.location != nil {
+		return c.location
+	}
+
+	return t.Location()
+}
+
+// matches reports whether a wall clock time's date and time, apart from the year, match the schedule.
+func (c *CronSchedule) matches(wall time.Time) (monthMatch, dayMatch, hourMatch, minuteMatch, secondMatch bool) {
+	return c.months&(1<<uint(wall.Month())) != 0,
+		c.matchesDay(wall),
+		c.hours&(1<<uint(wall.Hour())) != 0,
+		c.minutes&(1<<uint(wall.Minute())) != 0,
+		c.seconds&(1<<uint(wall.Second())) != 0
+}
+
+// matchesDay reports whether a time's day matches the day of month and day of week fields.
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	day := t.Day()
+	weekday := t.Weekday()
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	// Check the day of month.
+	dayMatch := c.days&(1<<uint(day)) != 0 || c.lastDayOffsets&(1<<uint(lastDay-day)) != 0
+	if !dayMatch && weekday != time.Saturday && weekday != time.Sunday {
+		if c.lastWeekday && (day == lastDay || day >= lastDay-2 && weekday == time.Friday) {
+			dayMatch = true
+		}
+		for target := 1; !dayMatch && target <= lastDay; target++ {
+			if c.nearestWeekdays&(1<<uint(target)) != 0 && nearestWeekday(t.Year(), t.Month(), target, lastDay) == day {
+				dayMatch = true
+			}
+		}
+	}
+
+	// Check the day of week.
+	weekdayMatch := c.weekdays&(1<<uint(weekday)) != 0 ||
+		c.lastWeekdays&(1<<uint(weekday)) != 0 && day+7 > lastDay ||
+		c.nthWeekdays[weekday]&(1<<uint((day-1)/7+1)) != 0
+	if c.dayStar || c.weekdayStar {
+		return dayMatch && weekdayMatch
+	}
+
+	return dayMatch || weekdayMatch
+}
+
+// nextWall returns the first wall clock time at or after the given one that matches the schedule, or the zero time if there is none by the end of the limit year.
+// Wall clock times are represented in UTC, so that they can be stepped without daylight saving changes.
+func (c *CronSchedule) nextWall(t time.Time, limit int) time.Time {
+	for t.Year() <= limit {
+		if c.years != nil && !c.years[t.Year()] {
+			t = time.Date(t.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		monthMatch, dayMatch, hourMatch, minuteMatch, secondMatch := c.matches(t)
+		switch {
+		case !monthMatch:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !dayMatch:
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !hourMatch:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case !minuteMatch:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, time.UTC)
+		case !secondMatch:
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// parseDays parses the day of month field.
+func (c *CronSchedule) parseDays(input string) error {
+	for _, part := range strings.Split(input, ",") {
+		upper := strings.ToUpper(part)
+		switch {
+		case upper == "L":
+			c.lastDayOffsets |= 1
+		case upper == "LW":
+			c.lastWeekday = true
+		case strings.HasPrefix(upper, "L-"):
+			offset, err := strconv.Atoi(part[2:])
+			if err != nil || offset < 0 || offset > 30 {
+				return errors.New("invalid " + cronDay.name + " offset: " + part)
+			}
+			c.lastDayOffsets |= 1 << uint(offset)
+		case len(upper) > 1 && strings.HasSuffix(upper, "W"):
+			day, err := parseCronValue(part[:len(part)-1], cronDay)
+			if err != nil {
+				return err
+			}
+			c.nearestWeekdays |= 1 << uint(day)
+		default:
+			bits, err := parseCronField(part, cronDay)
+			if err != nil {
+				return err
+			}
+			c.days |= bits
+		}
+	}
+
+	return nil
+}
+
+// parseWeekdays parses the day of week field.
+func (c *CronSchedule) parseWeekdays(input string) error {
+	for _, part := range strings.Split(input, ",") {
+		switch {
+		case len(part) > 1 && strings.HasSuffix(strings.ToUpper(part), "L"):
+			weekday, err := parseCronValue(part[:len(part)-1], cronWeekday)
+			if err != nil {
+				return err
+			}
+			c.lastWeekdays |= 1 << uint(weekday%7)
+		case strings.Contains(part, "#"):
+			index := strings.Index(part, "#")
+			weekday, err := parseCronValue(part[:index], cronWeekday)
+			if err != nil {
+				return err
+			}
+			occurrence, err := strconv.Atoi(part[index+1:])
+			if err != nil || occurrence < 1 || occurrence > 5 {
+				return errors.New("invalid " + cronWeekday.name + " occurrence: " + part)
+			}
+			c.nthWeekdays[weekday%7] |= 1 << uint(occurrence)
+		default:
+			bits, err := parseCronField(part, cronWeekday)
+			if err != nil {
+				return err
+			}
+			c.weekdays |= bits
+		}
+	}
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+
+	return nil
+}
+
+// previousWall returns the last wall clock time at or before the given one that matches the schedule, or the zero time if there is none by the start of the limit year.
+func (c *CronSchedule) previousWall(t time.Time, limit int) time.Time {
+	for t.Year() >= limit {
+		if c.years != nil && !c.years[t.Year()] {
+			t = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
+			continue
+		}
+		monthMatch, dayMatch, hourMatch, minuteMatch, secondMatch := c.matches(t)
+		switch {
+		case !monthMatch:
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
+		case !dayMatch:
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Add(-time.Second)
+		case !hourMatch:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.UTC).Add(-time.Second)
+		case !minuteMatch:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC).Add(-time.Second)
+		case !secondMatch:
+			t = t.Add(-time.Second)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// cronInstant returns the time at which a wall clock time occurs in a location.
+// Wall clock times skipped by a daylight saving change occur when the change happens, and repeated ones occur at their first occurrence.
+func cronInstant(wall time.Time, location *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, location)
+	start, end := t.ZoneBounds()
+
+	// Check for a skipped time, which time.Date moves to one side of the change.
+	if normalized := cronWall(t); !normalized.Equal(wall) {
+		if normalized.After(wall) {
+			return start
+		}
+		return end
+	}
+
+	// Check for a repeated time, which time.Date may place in either occurrence.
+	if !start.IsZero() {
+		_, offset := t.Zone()
+		_, previousOffset := start.Add(-time.Nanosecond).Zone()
+		if previousOffset > offset {
+			if earlier := t.Add(-time.Duration(previousOffset-offset) * time.Second); earlier.Before(start) && cronWall(earlier).Equal(wall) {
+				return earlier
+			}
+		}
+	}
+
+	return t
+}
+
+// cronWall returns a time's wall clock reading to the second, represented in UTC.
+func cronWall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// forEachCronValue calls set with each value permitted by a cron field.
+func forEachCronValue(input string, field cronField, set func(value int)) error {
+	for _, part := range strings.Split(input, ",") {
+		// Parse step.
+		step := 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[index+1:]); err != nil || step < 1 {
+				return errors.New("invalid " + field.name + " step: " + part)
+			}
+			part = part[:index]
+		}
+
+		// Parse range. Open-ended ranges stop at openMax, so that "1/2" is Monday, Wednesday and Friday rather than also Sunday as 7.
+		openMax := field.max
+		if field.openMax > 0 {
+			openMax = field.openMax
+		}
+		low, high := field.min, openMax
+		if part != "*" && part != "?" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = parseCronValue(bounds[0], field); err != nil {
+				return err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = parseCronValue(bounds[1], field); err != nil {
+					return err
+				}
+			} else if step > 1 {
+				high = openMax
+			}
+			if high < low {
+				return errors.New("invalid " + field.name + " range: " + part)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			set(value)
+		}
+	}
+
+	return nil
+}
+
+// nearestWeekday returns the weekday nearest a day of the month, without leaving the month.
+func nearestWeekday(year int, month time.Month, day int, lastDay int) int {
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == lastDay {
+			return day - 2
+		}
+		return day + 1
+	default:
+		return day
+	}
+}
+
+// parseCronField parses a cron field into a bit set of permitted values.
+func parseCronField(input string, field cronField) (uint64, error) {
+	var bits uint64
+	err := forEachCronValue(input, field, func(value int) {
+		bits |= 1 << uint(value)
+	})
+
+	return bits, err
+}
+
+// parseCronValue parses a single cron field value or name.
+func parseCronValue(input string, field cronField) (int, error) {
+	if value, ok := field.names[strings.ToLower(input)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(input)
+	if err != nil || value < field.min || value > field.max {
+		return 0, errors.New("invalid " + field.name + ": " + input)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,182 @@
+package time
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseCron tests ParseCron().
+func TestParseCron(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 12, 30, 45, 0, time.UTC) // A Monday.
+	tests := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.January, 1, 12, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.January, 1, 12, 45, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2024, time.January, 1, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 6 * * 0", time.Date(2024, time.January, 7, 6, 30, 0, 0, time.UTC)},
+		{"30 6 * * 7", time.Date(2024, time.January, 7, 6, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * fri", time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{"5,10 1 * FEB,dec *", time.Date(2024, time.February, 1, 1, 5, 0, 0, time.UTC)},
+		{"10/20 * * * *", time.Date(2024, time.January, 1, 12, 50, 0, 0, time.UTC)},
+		{"0 0 */2 * mon", time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)},
+
+		// Seconds and years.
+		{"*/20 * * * * *", time.Date(2024, time.January, 1, 12, 31, 0, 0, time.UTC)},
+		{"30 0 0 1 1 ? 2026", time.Date(2026, time.January, 1, 0, 0, 30, 0, time.UTC)},
+
+		// Modifiers.
+		{"0 0 L * *", time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 L-2 * *", time.Date(2024, time.January, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15W * *", time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1W jun *", time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30W jun *", time.Date(2024, time.June, 28, 0, 0, 0, 0, time.UTC)},
+		{"0 0 LW mar *", time.Date(2024, time.March, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 5L", time.Date(2024, time.January, 26, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * fri#2", time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC)},
+
+		// Macros.
+		{"@hourly", time.Date(2024, time.January, 1, 13, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := ParseCron(test.expression)
+		assert.NoError(t, err, test.expression)
+		assert.Equal(t, test.expected, schedule.Next(start), test.expression)
+	}
+
+	// Test open-ended weekday steps not reaching Sunday as 7.
+	schedule, err := ParseCron("0 0 * * 1/2")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC)))
+
+	// Test an impossible date.
+	schedule, err = ParseCron("0 0 30 feb *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(start).IsZero(), "No match.")
+
+	// Test invalid expressions.
+	for _, expression := range []string{"", "* * * *", "* * * * * * * *", "@reboot", "TZ=Nowhere/Special * * * * *", "* * L-31 * *", "* * 32W * *", "* * * * 8L", "* * * * 5#6", "* * * * L", "* * * * * * 1969", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err = ParseCron(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+// TestCronPrevious tests CronSchedule.Previous().
+func TestCronPrevious(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.January, 1, 12, 30, 0, 0, time.UTC)},
+		{"*/20 * * * * *", time.Date(2024, time.January, 1, 12, 30, 40, 0, time.UTC)},
+		{"0 0 L * *", time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * fri#2", time.Date(2023, time.December, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 0 1 1 ? 2020", time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := ParseCron(test.expression)
+		assert.NoError(t, err, test.expression)
+		assert.Equal(t, test.expected, schedule.Previous(start), test.expression)
+		next := schedule.Next(test.expected)
+		assert.True(t, next.IsZero() || next.After(start), "No match between: "+test.expression)
+	}
+
+	// Test a boundary.
+	schedule := mustParseCron(t, "* * * * *")
+	assert.Equal(t, time.Date(2024, time.January, 1, 12, 30, 0, 0, time.UTC), schedule.Previous(time.Date(2024, time.January, 1, 12, 31, 0, 0, time.UTC)), "Strictly before.")
+	assert.True(t, mustParseCron(t, "0 0 30 feb *").Previous(start).IsZero(), "No match.")
+}
+
+// TestCronDaylightSaving tests CronSchedule across daylight saving changes.
+func TestCronDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// Test a skipped time firing when the clocks change.
+	schedule := mustParseCron(t, "30 2 * * *")
+	assert.True(t, utc(time.March, 10, 7, 0).Equal(schedule.Next(utc(time.March, 10, 5, 0).In(newYork))), "Skipped time.")
+	assert.True(t, utc(time.March, 11, 6, 30).Equal(schedule.Next(utc(time.March, 10, 7, 0).In(newYork))), "Day after.")
+	assert.True(t, utc(time.March, 31, 1, 0).Equal(schedule.Next(utc(time.March, 30, 23, 0).In(berlin))), "Skipped time east of UTC.")
+	assert.True(t, utc(time.March, 31, 1, 0).Equal(schedule.Previous(utc(time.March, 31, 1, 10).In(berlin))), "Previous skipped time.")
+	schedule = mustParseCron(t, "CRON_TZ=America/New_York */15 * * * *")
+	assert.Equal(t, newYork, schedule.Location())
+	assert.True(t, utc(time.March, 10, 7, 0).Equal(schedule.Next(utc(time.March, 10, 6, 50))), "Skipped quarter hours.")
+	assert.True(t, utc(time.March, 10, 7, 15).Equal(schedule.Next(utc(time.March, 10, 7, 0))), "After the change.")
+	assert.Equal(t, newYork, schedule.Next(utc(time.March, 10, 7, 0)).Location(), "Schedule time zone.")
+
+	// Test a repeated time firing once.
+	schedule = mustParseCron(t, "30 1 * * *")
+	assert.True(t, utc(time.November, 3, 5, 30).Equal(schedule.Next(utc(time.November, 3, 4, 0).In(newYork))), "First occurrence.")
+	assert.True(t, utc(time.November, 4, 6, 30).Equal(schedule.Next(utc(time.November, 3, 5, 30).In(newYork))), "Second occurrence skipped.")
+	schedule = mustParseCron(t, "30 2 * * *")
+	assert.True(t, utc(time.October, 27, 0, 30).Equal(schedule.Next(utc(time.October, 26, 22, 0).In(berlin))), "First occurrence east of UTC.")
+	assert.True(t, utc(time.October, 28, 1, 30).Equal(schedule.Next(utc(time.October, 27, 0, 30).In(berlin))), "Second occurrence skipped east of UTC.")
+	schedule = mustParseCron(t, "*/30 * * * *")
+	assert.True(t, utc(time.November, 3, 7, 0).Equal(schedule.Next(utc(time.November, 3, 5, 30).In(newYork))), "Repeated hour skipped.")
+	assert.True(t, utc(time.November, 3, 5, 30).Equal(schedule.Previous(utc(time.November, 3, 7, 0).In(newYork))), "Previous before repeated hour.")
+	assert.True(t, utc(time.November, 3, 5, 30).Equal(schedule.Previous(utc(time.November, 3, 6, 40).In(newYork))), "Previous during repeated hour.")
+}
+
+// TestSleepUntilNext tests SleepUntilNext().
+func TestSleepUntilNext(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 12, 30, 45, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	// Test sleeping until the next time.
+	type result struct {
+		err  error
+		next time.Time
+	}
+	results := make(chan result, 1)
+	go func() {
+		next, err := SleepUntilNextWith(context.Background(), clock, "0 13 * * *")
+		results <- result{err: err, next: next}
+	}()
+	clock.BlockUntil(1)
+	clock.Set(time.Date(2024, time.January, 1, 13, 0, 0, 0, time.UTC))
+	slept := <-results
+	assert.NoError(t, slept.err)
+	assert.Equal(t, time.Date(2024, time.January, 1, 13, 0, 0, 0, time.UTC), slept.next)
+
+	// Test cancellation.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := SleepUntilNext(ctx, "@yearly")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Test invalid and impossible expressions.
+	_, err = SleepUntilNextWith(context.Background(), clock, "invalid")
+	assert.Error(t, err)
+	_, err = SleepUntilNextWith(context.Background(), clock, "0 0 30 feb *")
+	assert.Error(t, err)
+}
+
+// mustParseCron parses a cron expression, failing the test on error.
+func mustParseCron(t *testing.T, expression string) *CronSchedule {
+	schedule, err := ParseCron(expression)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return schedule
+}
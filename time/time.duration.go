@@ -0,0 +1,245 @@
+package time
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidDuration is returned when a string is not a valid ISO 8601 duration.
+	ErrInvalidDuration = errors.New("invalid ISO 8601 duration")
+)
+
+// Duration is an ISO 8601 duration such as "P1Y2M10DT2H30M".
+// Years, months, weeks and days are calendar units whose length depends on the time they are applied to, so a Duration only has a fixed length relative to an anchor time.
+type Duration struct {
+	Days     int           // Calendar days.
+	Months   int           // Calendar months.
+	Negative bool          // Whether the duration counts backwards.
+	Time     time.Duration // Hours, minutes and seconds.
+	Weeks    int           // Calendar weeks.
+	Years    int           // Calendar years.
+}
+
+// durationUnit defines a designator in an ISO 8601 duration.
+type durationUnit struct {
+	designator byte
+	length     time.Duration
+}
+
+// Duration designators, in the order they must appear.
+var (
+	dateDurationUnits = []durationUnit{{designator: 'Y'}, {designator: 'M'}, {designator: 'W'}, {designator: 'D'}}
+	timeDurationUnits = []durationUnit{{designator: 'H', length: time.Hour}, {designator: 'M', length: time.Minute}, {designator: 'S', length: time.Second}}
+)
+
+// FormatDuration formats an ISO 8601 duration. Hours, minutes and seconds are normalized, so "PT90M" formats as "PT1H30M", and the zero duration formats as "PT0S".
+// A duration whose components are all negative formats with a leading sign, so Duration{Time: -time.Hour} formats as "-PT1H"; components of mixed signs are signed individually, as in "P1MT-1H".
+func FormatDuration(duration Duration) string {
+	// Normalize the sign.
+	hasPositive := duration.Years > 0 || duration.Months > 0 || duration.Weeks > 0 || duration.Days > 0 || duration.Time > 0
+	hasNegative := duration.Years < 0 || duration.Months < 0 || duration.Weeks < 0 || duration.Days < 0 || duration.Time < 0
+	if hasNegative && !hasPositive {
+		duration = Duration{
+			Days:     -duration.Days,
+			Months:   -duration.Months,
+			Negative: !duration.Negative,
+			Time:     -duration.Time,
+			Weeks:    -duration.Weeks,
+			Years:    -duration.Years,
+		}
+	}
+
+	var builder strings.Builder
+	if duration.Negative {
+		builder.WriteByte('-')
+	}
+	builder.WriteByte('P')
+	for _, component := range []struct {
+		designator byte
+		value      int
+	}{{'Y', duration.Years}, {'M', duration.Months}, {'W', duration.Weeks}, {'D', duration.Days}} {
+		if component.value != 0 {
+			builder.WriteString(strconv.Itoa(component.value))
+			builder.WriteByte(component.designator)
+		}
+	}
+
+	// Write the time.
+	remaining := duration.Time
+	sign := ""
+	if remaining < 0 {
+		remaining = -remaining
+		sign = "-"
+	}
+	if remaining == 0 && (duration.Years != 0 || duration.Months != 0 || duration.Weeks != 0 || duration.Days != 0) {
+		return builder.String()
+	}
+	builder.WriteByte('T')
+	if hours := remaining / time.Hour; hours > 0 {
+		builder.WriteString(sign + strconv.FormatInt(int64(hours), 10) + "H")
+	}
+	if minutes := remaining % time.Hour / time.Minute; minutes > 0 {
+		builder.WriteString(sign + strconv.FormatInt(int64(minutes), 10) + "M")
+	}
+	if seconds := remaining % time.Minute; seconds > 0 || remaining == 0 {
+		builder.WriteString(sign + strconv.FormatInt(int64(seconds/time.Second), 10))
+		if fraction := seconds % time.Second; fraction > 0 {
+			builder.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", fraction), "0"))
+		}
+		builder.WriteByte('S')
+	}
+
+	return builder.String()
+}
+
+// ParseDuration parses an ISO 8601 duration such as "P1Y2M10DT2H30M", "P2W" or "-PT1.5S".
+// A leading sign is accepted, and individual components may be negative, as in "P1MT-1H", so that durations with components of mixed signs can be represented.
+// Only the last of the hour, minute and second components may have a fraction, separated by a dot or comma.
+func ParseDuration(input string) (Duration, error) {
+	duration := Duration{}
+	rest := input
+	if strings.HasPrefix(rest, "-") {
+		duration.Negative = true
+		rest = rest[1:]
+	} else {
+		rest = strings.TrimPrefix(rest, "+")
+	}
+	if !strings.HasPrefix(rest, "P") || len(rest) < 3 {
+		return Duration{}, fmt.Errorf("%w: %s", ErrInvalidDuration, input)
+	}
+	rest = rest[1:]
+
+	// Parse the date components.
+	datePart, timePart, hasTime := strings.Cut(rest, "T")
+	if hasTime && timePart == "" {
+		return Duration{}, fmt.Errorf("%w: %s", ErrInvalidDuration, input)
+	}
+	dateFields := []*int{&duration.Years, &duration.Months, &duration.Weeks, &duration.Days}
+	unit := 0
+	for datePart != "" {
+		number, designator, remaining, ok := cutDurationComponent(datePart)
+		for ok && unit < len(dateDurationUnits) && dateDurationUnits[unit].designator != designator {
+			unit++
+		}
+		if !ok || unit == len(dateDurationUnits) || strings.ContainsAny(number, ".,") {
+			return Duration{}, fmt.Errorf("%w: %s", ErrInvalidDuration, input)
+		}
+		value, err := strconv.Atoi(number)
+		if err != nil {
+			return Duration{}, fmt.Errorf("%w: %s", ErrInvalidDuration, input)
+		}
+		*dateFields[unit] = value
+		datePart = remaining
+		unit++
+	}
+
+	// Parse the time components.
+	unit = 0
+	for timePart != "" {
+		number, designator, remaining, ok := cutDurationComponent(timePart)
+		for ok && unit < len(timeDurationUnits) && timeDurationUnits[unit].designator != designator {
+			unit++
+		}
+		if !ok || unit == len(timeDurationUnits) || (remaining != "" && strings.ContainsAny(number, ".,")) {
+			return Duration{}, fmt.Errorf("%w: %s", ErrInvalidDuration, input)
+		}
+		value, err := parseDurationNumber(strings.TrimPrefix(number, "-"), timeDurationUnits[unit].length)
+		if err != nil {
+			return Duration{}, fmt.Errorf("%w: %s", ErrInvalidDuration, input)
+		}
+		if strings.HasPrefix(number, "-") {
+			value = -value
+		}
+		duration.Time += value
+		timePart = remaining
+		unit++
+	}
+
+	return duration, nil
+}
+
+// AddTo returns t with the duration added, applying calendar units in t's location before hours, minutes and seconds.
+// For example, "P1D" keeps the wall clock time across a daylight saving change while "PT24H" adds exactly 24 hours.
+// Years and months that land past the end of a month are clamped to its last day, so "P1M" after January 31, 2024 is February 29.
+func (d Duration) AddTo(t time.Time) time.Time {
+	return d.addTo(t, 1)
+}
+
+// Length returns the exact length of the duration when added to anchor.
+func (d Duration) Length(anchor time.Time) time.Duration {
+	return d.AddTo(anchor).Sub(anchor)
+}
+
+// String formats the duration as ISO 8601.
+func (d Duration) String() string {
+	return FormatDuration(d)
+}
+
+// addTo returns t with the duration added factor times. Multiplying before adding avoids drift, so adding "P1M" three times to January 31 gives April 30 rather than April 29.
+func (d Duration) addTo(t time.Time, factor int) time.Time {
+	if d.Negative {
+		factor = -factor
+	}
+
+	// Add years and months, clamping the day to the target month.
+	if months := factor * (12*d.Years + d.Months); months != 0 {
+		year, month, day := t.Date()
+		hour, minute, second := t.Clock()
+		months += int(month) - 1
+		year += months / 12
+		if months %= 12; months < 0 {
+			months += 12
+			year--
+		}
+		month = time.Month(months + 1)
+		if lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > lastDay {
+			day = lastDay
+		}
+		t = time.Date(year, month, day, hour, minute, second, t.Nanosecond(), t.Location())
+	}
+
+	return t.AddDate(0, 0, factor*(7*d.Weeks+d.Days)).Add(time.Duration(factor) * d.Time)
+}
+
+// cutDurationComponent splits the leading number, which may be negative, and designator from part of a duration.
+func cutDurationComponent(input string) (number string, designator byte, rest string, ok bool) {
+	start := 0
+	if strings.HasPrefix(input, "-") {
+		start = 1
+	}
+	i := start
+	for i < len(input) && (input[i] >= '0' && input[i] <= '9' || input[i] == '.' || input[i] == ',') {
+		i++
+	}
+	if i == start || i == len(input) {
+		return "", 0, "", false
+	}
+
+	return input[:i], input[i], input[i+1:], true
+}
+
+// parseDurationNumber parses a non-negative decimal number of units, with up to nanosecond precision.
+func parseDurationNumber(number string, unit time.Duration) (time.Duration, error) {
+	whole, fraction, _ := strings.Cut(strings.Replace(number, ",", ".", 1), ".")
+	if !isDigits(whole) || (fraction != "" && !isDigits(fraction)) {
+		return 0, ErrInvalidDuration
+	}
+	value, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || value > int64(1<<63-1)/int64(unit) {
+		return 0, ErrInvalidDuration
+	}
+	result := time.Duration(value) * unit
+	if fraction != "" {
+		if len(fraction) > 9 {
+			fraction = fraction[:9]
+		}
+		nanoseconds, _ := strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64) // nolint
+		result += time.Duration(nanoseconds) * (unit / time.Second)
+	}
+
+	return result, nil
+}
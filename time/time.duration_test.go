@@ -0,0 +1,79 @@
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseDuration tests ParseDuration() and FormatDuration().
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  Duration
+		formatted string
+	}{
+		{"P1Y2M10DT2H30M", Duration{Years: 1, Months: 2, Days: 10, Time: 2*time.Hour + 30*time.Minute}, "P1Y2M10DT2H30M"},
+		{"P2W", Duration{Weeks: 2}, "P2W"},
+		{"P1M", Duration{Months: 1}, "P1M"},
+		{"PT1M", Duration{Time: time.Minute}, "PT1M"},
+		{"PT90M", Duration{Time: 90 * time.Minute}, "PT1H30M"},
+		{"PT36H", Duration{Time: 36 * time.Hour}, "PT36H"},
+		{"-PT1.5S", Duration{Negative: true, Time: 1500 * time.Millisecond}, "-PT1.5S"},
+		{"PT0,000000001S", Duration{Time: time.Nanosecond}, "PT0.000000001S"},
+		{"PT0.5H", Duration{Time: 30 * time.Minute}, "PT30M"},
+		{"+P1D", Duration{Days: 1}, "P1D"},
+		{"PT0S", Duration{}, "PT0S"},
+		{"P-1D", Duration{Days: -1}, "-P1D"},
+		{"P1MT-1H", Duration{Months: 1, Time: -time.Hour}, "P1MT-1H"},
+		{"P1DT-1H-30M", Duration{Days: 1, Time: -90 * time.Minute}, "P1DT-1H-30M"},
+	}
+	for _, test := range tests {
+		result, err := ParseDuration(test.input)
+		if assert.NoError(t, err, test.input) {
+			assert.Equal(t, test.expected, result, test.input)
+			assert.Equal(t, test.formatted, FormatDuration(result), test.input)
+			assert.Equal(t, test.formatted, result.String(), test.input)
+		}
+	}
+
+	// Test invalid durations.
+	for _, input := range []string{"", "P", "PT", "1D", "P1", "PD", "P1DT", "P1.5D", "PT1.5H30M", "P1D1Y", "PT1S1M", "P1H", "PT1D", "P--1D", "PT-H", "P-", "PT99999999999999H"} {
+		_, err := ParseDuration(input)
+		assert.ErrorIs(t, err, ErrInvalidDuration, input)
+	}
+
+	// Test normalizing negative components.
+	assert.Equal(t, "-PT1H", FormatDuration(Duration{Time: -time.Hour}))
+	assert.Equal(t, "-P1Y2M", FormatDuration(Duration{Years: -1, Months: -2}))
+	assert.Equal(t, "P1D", FormatDuration(Duration{Negative: true, Days: -1}))
+	for _, duration := range []Duration{{Time: -time.Hour}, {Years: -1, Months: 2}, {Negative: true, Days: -1, Time: time.Second}} {
+		parsed, err := ParseDuration(FormatDuration(duration))
+		if assert.NoError(t, err, FormatDuration(duration)) {
+			assert.Equal(t, duration.AddTo(time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)), parsed.AddTo(time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)), FormatDuration(duration))
+		}
+	}
+}
+
+// TestDurationAddTo tests Duration.AddTo() and Duration.Length().
+func TestDurationAddTo(t *testing.T) {
+	anchor := time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC), Duration{Months: 1}.AddTo(anchor), "Clamped to the end of the month.")
+	assert.Equal(t, time.Date(2024, time.April, 30, 12, 0, 0, 0, time.UTC), Duration{Months: 1}.addTo(anchor, 3), "Multiplied without drift.")
+	assert.Equal(t, time.Date(2023, time.November, 30, 12, 0, 0, 0, time.UTC), Duration{Negative: true, Months: 2}.AddTo(anchor), "Negative months clamped.")
+	assert.Equal(t, time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC), Duration{Years: 1}.AddTo(time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)), "Leap day clamped.")
+	assert.Equal(t, time.Date(2025, time.March, 11, 14, 30, 0, 0, time.UTC), Duration{Years: 1, Months: 1, Days: 10, Time: 2*time.Hour + 30*time.Minute}.AddTo(anchor.AddDate(0, 0, 1)))
+	assert.Equal(t, time.Date(2024, time.January, 17, 12, 0, 0, 0, time.UTC), Duration{Negative: true, Weeks: 2}.AddTo(anchor), "Negative.")
+	assert.Equal(t, 29*24*time.Hour, Duration{Months: 1}.Length(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)), "Leap February.")
+
+	// Test calendar days across a daylight saving change.
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	beforeChange := time.Date(2024, time.March, 9, 12, 0, 0, 0, location)
+	assert.Equal(t, time.Date(2024, time.March, 10, 12, 0, 0, 0, location), Duration{Days: 1}.AddTo(beforeChange), "Wall clock kept.")
+	assert.Equal(t, 23*time.Hour, Duration{Days: 1}.Length(beforeChange), "Short day.")
+	assert.Equal(t, 24*time.Hour, Duration{Time: 24 * time.Hour}.Length(beforeChange), "Exact hours.")
+}
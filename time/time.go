@@ -1,9 +1,8 @@
-// Package time provides functions for parsing time and sleeping.
+// Package time provides functions for parsing times, durations and intervals, and for sleeping.
 package time
 
 import (
-	"math/rand"
-	"strings"
+	"context"
 	"time"
 )
 
@@ -28,35 +27,65 @@ var (
 	}
 )
 
+// IncrementalDelay returns the delay SleepIncrementalWith sleeps for an increment, drawing jitter from jitterSource.
+func IncrementalDelay(jitterSource JitterSource, increment int) time.Duration {
+	if increment < 0 {
+		increment = 0
+	}
+	if increment > 8 {
+		increment = 8
+	}
+
+	return increments[increment].FixedAmount + time.Duration(jitterSource.Int63n(int64(increments[increment].VariableAmount)))
+}
+
 // Parse parses an arbitrary time string, attempting to determine its layout.
+// Ambiguous numeric dates are read month first; use ParseLocale to choose the order or to find the matched layout.
 func Parse(input string) (time.Time, error) {
-	var format string
-	if strings.HasSuffix(input, "Z") {
-		format = "2006-01-02T15:04:05Z"
-	} else {
-		format = "2006-01-02T15:04:05-07:00"
+	parsed, _, err := ParseLocale(input, "")
+	return parsed, err
+}
+
+// SleepContext sleeps for a duration or until the context is done, returning the context's error if it ended the sleep.
+func SleepContext(ctx context.Context, duration time.Duration) error {
+	return SleepContextWith(ctx, RealClock, duration)
+}
+
+// SleepContextWith sleeps for a duration on clock or until the context is done, returning the context's error if it ended the sleep.
+func SleepContextWith(ctx context.Context, clock Clock, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
 	}
 
-	return time.Parse(format, input)
+	timer := clock.NewTimer(duration)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
 }
 
 // SleepIncremental sleeps using incremental backoff.
 func SleepIncremental(increment int) {
-	if increment < 0 {
-		increment = 0
-	}
-	if increment > 8 {
-		increment = 8
-	}
+	SleepIncrementalWith(RealClock, DefaultJitterSource, increment)
+}
 
-	delay := increments[increment].FixedAmount + time.Duration(rand.Int31n(int32(increments[increment].VariableAmount)))
-	time.Sleep(delay)
+// SleepIncrementalWith sleeps on clock using incremental backoff, drawing jitter from jitterSource.
+func SleepIncrementalWith(clock Clock, jitterSource JitterSource, increment int) {
+	clock.Sleep(IncrementalDelay(jitterSource, increment))
 }
 
 // SleepUntil sleeps until a specified time.
 func SleepUntil(when time.Time) {
-	now := time.Now()
+	SleepUntilWith(RealClock, when)
+}
+
+// SleepUntilWith sleeps on clock until a specified time.
+func SleepUntilWith(clock Clock, when time.Time) {
+	now := clock.Now()
 	if now.Before(when) {
-		time.Sleep(when.Sub(now))
+		clock.Sleep(when.Sub(now))
 	}
 }
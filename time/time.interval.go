@@ -0,0 +1,218 @@
+package time
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidInterval is returned when a string is not a valid ISO 8601 interval.
+	ErrInvalidInterval = errors.New("invalid ISO 8601 interval")
+)
+
+// Interval is the half-open time interval from Start up to, but not including, End.
+type Interval struct {
+	End   time.Time // End of the interval, excluded.
+	Start time.Time // Start of the interval, included.
+}
+
+// RecurringInterval is an ISO 8601 recurring interval such as "R5/2024-01-01T00:00:00Z/P1M": consecutive intervals of one period each.
+// Recurrences count forwards from Start or, if Start is zero, backwards from End.
+type RecurringInterval struct {
+	End         time.Time // End of the last interval, for recurrences counting backwards.
+	Period      Duration  // Length of each interval.
+	Repetitions int       // Number of intervals, or -1 if unbounded.
+	Start       time.Time // Start of the first interval, for recurrences counting forwards.
+}
+
+// ParseInterval parses an ISO 8601 interval: "start/end", "start/duration" or "duration/end".
+// Times are parsed with Parse, and durations with ParseDuration.
+func ParseInterval(input string) (Interval, error) {
+	start, end, period, err := parseIntervalParts(input)
+	if err != nil {
+		return Interval{}, err
+	}
+	switch {
+	case start.IsZero():
+		start = period.addTo(end, -1)
+	case end.IsZero():
+		end = period.AddTo(start)
+	}
+	if end.Before(start) {
+		return Interval{}, fmt.Errorf("%w: %s", ErrInvalidInterval, input)
+	}
+
+	return Interval{End: end, Start: start}, nil
+}
+
+// ParseRecurringInterval parses an ISO 8601 recurring interval: "Rn/" followed by an interval, where n is the number of intervals and is omitted if unbounded.
+// A "start/end" interval recurs with the exact length between its times. Unbounded recurrences must have a start.
+func ParseRecurringInterval(input string) (RecurringInterval, error) {
+	if !strings.HasPrefix(input, "R") {
+		return RecurringInterval{}, fmt.Errorf("%w: %s", ErrInvalidInterval, input)
+	}
+	count, interval, ok := strings.Cut(input[1:], "/")
+	if !ok {
+		return RecurringInterval{}, fmt.Errorf("%w: %s", ErrInvalidInterval, input)
+	}
+	recurring := RecurringInterval{Repetitions: -1}
+	if count != "" {
+		if !isDigits(count) {
+			return RecurringInterval{}, fmt.Errorf("%w: %s", ErrInvalidInterval, input)
+		}
+		repetitions, err := strconv.Atoi(count)
+		if err != nil {
+			return RecurringInterval{}, fmt.Errorf("%w: %s", ErrInvalidInterval, input)
+		}
+		recurring.Repetitions = repetitions
+	}
+
+	// Parse the first interval.
+	start, end, period, err := parseIntervalParts(interval)
+	if err != nil {
+		return RecurringInterval{}, err
+	}
+	switch {
+	case start.IsZero() && recurring.Repetitions < 0:
+		return RecurringInterval{}, fmt.Errorf("%w: unbounded recurrence without a start: %s", ErrInvalidInterval, input)
+	case start.IsZero():
+		recurring.End = end
+	case end.IsZero():
+		recurring.Start = start
+	default:
+		recurring.Start = start
+		period = Duration{Time: end.Sub(start)}
+	}
+	anchor := start
+	if anchor.IsZero() {
+		anchor = end
+	}
+	if period.Length(anchor) <= 0 {
+		return RecurringInterval{}, fmt.Errorf("%w: period must be positive: %s", ErrInvalidInterval, input)
+	}
+	recurring.Period = period
+
+	return recurring, nil
+}
+
+// Contains reports whether t is within the interval.
+func (i Interval) Contains(t time.Time) bool {
+	return !t.Before(i.Start) && t.Before(i.End)
+}
+
+// Duration returns the length of the interval.
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// Overlaps reports whether the intervals share any time. Intervals that only touch do not overlap.
+func (i Interval) Overlaps(other Interval) bool {
+	return i.Start.Before(other.End) && other.Start.Before(i.End)
+}
+
+// String formats the interval as ISO 8601.
+func (i Interval) String() string {
+	return i.Start.Format(time.RFC3339Nano) + "/" + i.End.Format(time.RFC3339Nano)
+}
+
+// Intervals returns up to limit intervals in order; a negative limit returns none.
+func (r RecurringInterval) Intervals(limit int) []Interval {
+	if r.Repetitions >= 0 && limit > r.Repetitions {
+		limit = r.Repetitions
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	intervals := make([]Interval, 0, limit)
+	for n := 0; n < limit; n++ {
+		intervals = append(intervals, Interval{End: r.start(n + 1), Start: r.start(n)})
+	}
+
+	return intervals
+}
+
+// Next returns the start of the first interval after the given time, or the zero time if there is none, so that recurring intervals can be used as schedules.
+func (r RecurringInterval) Next(after time.Time) time.Time {
+	// Estimate the interval from the period's length, then correct for calendar variation.
+	first := r.start(0)
+	length := r.Period.Length(first)
+	if length <= 0 {
+		return time.Time{}
+	}
+	n := 0
+	if after.After(first) {
+		n = int(after.Sub(first) / length)
+	}
+	for n > 0 && r.start(n).After(after) {
+		n--
+	}
+	for !r.start(n).After(after) {
+		n++
+	}
+	if r.Repetitions >= 0 && n >= r.Repetitions {
+		return time.Time{}
+	}
+
+	return r.start(n)
+}
+
+// String formats the recurring interval as ISO 8601.
+func (r RecurringInterval) String() string {
+	count := ""
+	if r.Repetitions >= 0 {
+		count = strconv.Itoa(r.Repetitions)
+	}
+	if r.Start.IsZero() {
+		return "R" + count + "/" + r.Period.String() + "/" + r.End.Format(time.RFC3339Nano)
+	}
+
+	return "R" + count + "/" + r.Start.Format(time.RFC3339Nano) + "/" + r.Period.String()
+}
+
+// start returns the start of the nth interval, counting from zero.
+func (r RecurringInterval) start(n int) time.Time {
+	if r.Start.IsZero() {
+		return r.Period.addTo(r.End, n-r.Repetitions)
+	}
+
+	return r.Period.addTo(r.Start, n)
+}
+
+// parseIntervalParts parses the parts of an interval, returning a zero time for the part given as a duration.
+func parseIntervalParts(input string) (start time.Time, end time.Time, period Duration, err error) {
+	first, second, ok := strings.Cut(input, "/")
+	if !ok {
+		return time.Time{}, time.Time{}, Duration{}, fmt.Errorf("%w: %s", ErrInvalidInterval, input)
+	}
+	firstIsDuration := isDurationString(first)
+	secondIsDuration := isDurationString(second)
+	switch {
+	case firstIsDuration && secondIsDuration:
+		return time.Time{}, time.Time{}, Duration{}, fmt.Errorf("%w: %s", ErrInvalidInterval, input)
+	case firstIsDuration:
+		if period, err = ParseDuration(first); err == nil {
+			end, err = Parse(second)
+		}
+	case secondIsDuration:
+		if start, err = Parse(first); err == nil {
+			period, err = ParseDuration(second)
+		}
+	default:
+		if start, err = Parse(first); err == nil {
+			end, err = Parse(second)
+		}
+	}
+	if err != nil {
+		return time.Time{}, time.Time{}, Duration{}, fmt.Errorf("%w: %s: %s", ErrInvalidInterval, input, err.Error())
+	}
+
+	return start, end, period, nil
+}
+
+// isDurationString reports whether a string looks like an ISO 8601 duration rather than a time.
+func isDurationString(input string) bool {
+	return strings.HasPrefix(strings.TrimLeft(input, "+-"), "P")
+}
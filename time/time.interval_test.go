@@ -0,0 +1,90 @@
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseInterval tests ParseInterval().
+func TestParseInterval(t *testing.T) {
+	january := Interval{Start: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)}
+	for _, input := range []string{"2024-01-01/P1M", "2024-01-01T00:00:00Z/2024-02-01T00:00:00Z", "P1M/2024-02-01", "2024-01-01/P31D"} {
+		interval, err := ParseInterval(input)
+		if assert.NoError(t, err, input) {
+			assert.True(t, january.Start.Equal(interval.Start), input)
+			assert.True(t, january.End.Equal(interval.End), input)
+		}
+	}
+	assert.Equal(t, "2024-01-01T00:00:00Z/2024-02-01T00:00:00Z", january.String())
+	assert.Equal(t, 31*24*time.Hour, january.Duration())
+
+	// Test invalid intervals.
+	for _, input := range []string{"", "2024-01-01", "P1M/P1D", "2024-02-01/2024-01-01", "2024-01-01/-P1D", "today/P1D", "2024-01-01/P1X"} {
+		_, err := ParseInterval(input)
+		assert.ErrorIs(t, err, ErrInvalidInterval, input)
+	}
+}
+
+// TestInterval tests Interval.Contains() and Interval.Overlaps().
+func TestInterval(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	interval := Interval{Start: start, End: start.Add(time.Hour)}
+	assert.True(t, interval.Contains(start), "Start included.")
+	assert.True(t, interval.Contains(start.Add(30*time.Minute)), "Middle included.")
+	assert.False(t, interval.Contains(start.Add(time.Hour)), "End excluded.")
+	assert.False(t, interval.Contains(start.Add(-time.Nanosecond)), "Before excluded.")
+
+	assert.True(t, interval.Overlaps(Interval{Start: start.Add(59 * time.Minute), End: start.Add(2 * time.Hour)}), "Overlapping.")
+	assert.True(t, interval.Overlaps(Interval{Start: start.Add(time.Minute), End: start.Add(2 * time.Minute)}), "Contained.")
+	assert.False(t, interval.Overlaps(Interval{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)}), "Touching.")
+	assert.False(t, interval.Overlaps(Interval{Start: start.Add(-2 * time.Hour), End: start.Add(-time.Hour)}), "Disjoint.")
+}
+
+// TestParseRecurringInterval tests ParseRecurringInterval() and RecurringInterval.
+func TestParseRecurringInterval(t *testing.T) {
+	// Test monthly recurrences without drift.
+	recurring, err := ParseRecurringInterval("R5/2024-01-31T00:00:00Z/P1M")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, recurring.Repetitions)
+	assert.Equal(t, "R5/2024-01-31T00:00:00Z/P1M", recurring.String())
+	intervals := recurring.Intervals(10)
+	assert.Len(t, intervals, 5, "Limited by repetitions.")
+	assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), intervals[1].Start, "Clamped to the end of the month.")
+	assert.Equal(t, time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), intervals[2].Start, "No drift after clamping.")
+	assert.Equal(t, time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC), intervals[3].Start)
+	assert.Equal(t, intervals[3].End, intervals[4].Start, "Consecutive intervals.")
+	assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), recurring.Next(time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), recurring.Next(time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)), "Before the first interval.")
+	assert.True(t, recurring.Next(time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC)).IsZero(), "After the last interval.")
+
+	// Test unbounded recurrences of a start and end.
+	recurring, err = ParseRecurringInterval("R/2024-01-01T00:00:00Z/2024-01-01T06:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, recurring.Repetitions)
+	assert.Len(t, recurring.Intervals(3), 3)
+	assert.Empty(t, recurring.Intervals(-1), "Negative limit.")
+	assert.Equal(t, time.Date(2030, time.January, 1, 6, 0, 0, 0, time.UTC), recurring.Next(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)))
+
+	// Test recurrences counting back from an end.
+	recurring, err = ParseRecurringInterval("R3/P1D/2024-01-10T00:00:00Z")
+	assert.NoError(t, err)
+	intervals = recurring.Intervals(10)
+	assert.Len(t, intervals, 3)
+	assert.Equal(t, time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC), intervals[0].Start)
+	assert.Equal(t, time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC), intervals[2].End)
+	assert.Equal(t, "R3/P1D/2024-01-10T00:00:00Z", recurring.String())
+
+	// Test no recurrences.
+	recurring, err = ParseRecurringInterval("R0/2024-01-01/P1D")
+	assert.NoError(t, err)
+	assert.Empty(t, recurring.Intervals(10))
+	assert.True(t, recurring.Next(time.Time{}).IsZero())
+
+	// Test invalid recurrences.
+	for _, input := range []string{"", "2024-01-01/P1D", "R5", "Rx/2024-01-01/P1D", "R/P1D/2024-01-10", "R5/2024-01-01/PT0S", "R5/2024-01-01/2024-01-01"} {
+		_, err = ParseRecurringInterval(input)
+		assert.ErrorIs(t, err, ErrInvalidInterval, input)
+	}
+}
@@ -0,0 +1,335 @@
+package time
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts reported by ParseLocale for formats that the time package cannot describe.
+const (
+	LayoutISOWeek   = "ISO 8601 week date" // Week dates such as 2006-W01-1, 2006W011 or 2006-W01.
+	LayoutUnix      = "unix seconds"       // Seconds since the Unix epoch, optionally with a fraction.
+	LayoutUnixMicro = "unix microseconds"  // Microseconds since the Unix epoch.
+	LayoutUnixMilli = "unix milliseconds"  // Milliseconds since the Unix epoch.
+	LayoutUnixNano  = "unix nanoseconds"   // Nanoseconds since the Unix epoch.
+)
+
+const (
+	// minimumUnixDigits is the fewest digits in a Unix epoch timestamp, so that short numbers such as years are not read as times in 1970.
+	minimumUnixDigits = 9
+)
+
+// Numeric date orders.
+const (
+	dateOrderMDY dateOrder = iota // Month, day, year, as in the United States.
+	dateOrderDMY                  // Day, month, year, as in most of the world.
+	dateOrderYMD                  // Year, month, day, as in East Asia.
+)
+
+var (
+	// ErrUnknownTimeFormat is returned when a time string does not match any supported layout.
+	ErrUnknownTimeFormat = errors.New("unknown time format")
+
+	// mdyRegions are regions that write numeric dates month first.
+	mdyRegions = map[string]bool{"AS": true, "FM": true, "GU": true, "MH": true, "MP": true, "PH": true, "PR": true, "PW": true, "UM": true, "US": true, "VI": true}
+
+	// parseLayouts are the unambiguous layouts tried in order. Fractional seconds are accepted after any seconds field.
+	parseLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05 -0700 MST",
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05 MST",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"20060102T150405Z0700",
+		"20060102T150405",
+		time.RFC1123Z,
+		time.RFC1123,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"Mon, 2 Jan 2006 15:04 -0700",
+		"Mon, 2 Jan 2006 15:04 MST",
+		"2 Jan 2006 15:04:05 -0700",
+		"2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04 -0700",
+		"2 Jan 2006 15:04 MST",
+		time.RFC822Z,
+		time.RFC822,
+		time.RFC850,
+		time.ANSIC,
+		time.UnixDate,
+		time.RubyDate,
+		"Monday, January 2, 2006",
+		"Monday, 2 January 2006",
+		"January 2, 2006 15:04:05",
+		"January 2, 2006 3:04 PM",
+		"January 2, 2006",
+		"January 2 2006",
+		"Jan 2, 2006 15:04:05",
+		"Jan 2, 2006 3:04 PM",
+		"Jan 2, 2006",
+		"Jan 2 2006",
+		"2 January 2006 15:04:05",
+		"2 January 2006",
+		"2 Jan 2006",
+		"20060102150405",
+		"20060102",
+	}
+
+	// parseTimeLayouts are the times of day that may follow a numeric date.
+	parseTimeLayouts = []string{
+		"15:04:05",
+		"15:04",
+		"3:04:05 PM",
+		"3:04 PM",
+		"3:04:05PM",
+		"3:04PM",
+		"15:04:05Z07:00",
+		"15:04:05 -0700",
+		"15:04:05 MST",
+	}
+
+	// ymdLanguages are languages that write numeric dates year first.
+	ymdLanguages = map[string]bool{"hu": true, "ja": true, "ko": true, "lt": true, "mn": true, "zh": true}
+
+	// ymdRegions are regions that write numeric dates year first.
+	ymdRegions = map[string]bool{"CN": true, "HU": true, "JP": true, "KP": true, "KR": true, "LT": true, "MN": true, "TW": true}
+)
+
+// dateOrder is the order of the fields in a numeric date.
+type dateOrder int
+
+// ParseLocale parses an arbitrary time string, returning the time and the layout that matched.
+// Supported formats include RFC 3339 with optional fractional seconds, RFC 1123, RFC 822 and RFC 850 mail dates, Unix epoch seconds, milliseconds, microseconds and nanoseconds, ISO 8601 week dates and common numeric and written dates.
+// The locale is a BCP 47 tag such as "en-US" or "de_DE" that resolves whether an ambiguous numeric date such as 01/02/2006 is month first; it defaults to month first.
+// Epoch and week dates are returned in UTC and report one of the Layout constants; other formats report a time package layout.
+func ParseLocale(input string, locale string) (time.Time, string, error) {
+	// Remove whitespace and trailing zone comments, as in "Mon, 2 Jan 2006 15:04:05 -0700 (MST)".
+	input = strings.TrimSpace(input)
+	if strings.HasSuffix(input, ")") {
+		if i := strings.LastIndex(input, " ("); i > 0 {
+			input = strings.TrimSpace(input[:i])
+		}
+	}
+	if input == "" {
+		return time.Time{}, "", ErrUnknownTimeFormat
+	}
+
+	// Check numeric formats.
+	if parsed, layout, ok := parseUnix(input); ok {
+		return parsed, layout, nil
+	}
+	if parsed, ok := parseISOWeek(input); ok {
+		return parsed, LayoutISOWeek, nil
+	}
+
+	// Check unambiguous layouts.
+	for _, layout := range parseLayouts {
+		if parsed, err := time.Parse(layout, input); err == nil {
+			return parsed, layout, nil
+		}
+	}
+
+	// Check numeric dates.
+	if parsed, layout, ok := parseNumericDate(input, localeDateOrder(locale)); ok {
+		return parsed, layout, nil
+	}
+
+	return time.Time{}, "", ErrUnknownTimeFormat
+}
+
+// localeDateOrder returns the numeric date order for a locale.
+func localeDateOrder(locale string) dateOrder {
+	if locale == "" {
+		return dateOrderMDY
+	}
+
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for _, part := range parts[1:] {
+		if len(part) != 2 {
+			continue
+		}
+		region := strings.ToUpper(part)
+		if mdyRegions[region] {
+			return dateOrderMDY
+		}
+		if ymdRegions[region] {
+			return dateOrderYMD
+		}
+
+		return dateOrderDMY
+	}
+
+	language := strings.ToLower(parts[0])
+	if language == "en" {
+		return dateOrderMDY
+	}
+	if ymdLanguages[language] {
+		return dateOrderYMD
+	}
+
+	return dateOrderDMY
+}
+
+// parseISOWeek parses an ISO 8601 week date, with or without dashes and the day of the week.
+func parseISOWeek(input string) (time.Time, bool) {
+	if len(input) < 7 || !isDigits(input[:4]) {
+		return time.Time{}, false
+	}
+	year, _ := strconv.Atoi(input[:4]) // nolint
+	rest := input[4:]
+	dashed := strings.HasPrefix(rest, "-")
+	rest = strings.TrimPrefix(rest, "-")
+	if len(rest) < 3 || rest[0] != 'W' || !isDigits(rest[1:3]) {
+		return time.Time{}, false
+	}
+	week, _ := strconv.Atoi(rest[1:3]) // nolint
+	day := 1
+	if dayPart := rest[3:]; dayPart != "" {
+		if dashed {
+			if !strings.HasPrefix(dayPart, "-") {
+				return time.Time{}, false
+			}
+			dayPart = dayPart[1:]
+		}
+		if len(dayPart) != 1 || dayPart[0] < '1' || dayPart[0] > '7' {
+			return time.Time{}, false
+		}
+		day = int(dayPart[0] - '0')
+	}
+
+	// Week 1 is the week containing January 4.
+	january4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := january4.AddDate(0, 0, -((int(january4.Weekday()) + 6) % 7))
+	parsed := monday.AddDate(0, 0, (week-1)*7+day-1)
+	if parsedYear, parsedWeek := parsed.ISOWeek(); week < 1 || parsedYear != year || parsedWeek != week {
+		return time.Time{}, false
+	}
+
+	return parsed, true
+}
+
+// parseNumericDate parses three numbers separated by slashes, dots or dashes, optionally followed by a time of day.
+// A four-digit first field is always a year; otherwise the order comes from the locale unless a field greater than 12 rules it out.
+func parseNumericDate(input string, order dateOrder) (time.Time, string, bool) {
+	datePart, timePart := input, ""
+	if i := strings.IndexByte(input, ' '); i > 0 {
+		datePart, timePart = input[:i], strings.TrimSpace(input[i+1:])
+	}
+	separatorIndex := strings.IndexAny(datePart, "/.-")
+	if separatorIndex < 0 {
+		return time.Time{}, "", false
+	}
+	separator := datePart[separatorIndex : separatorIndex+1]
+	fields := strings.Split(datePart, separator)
+	if len(fields) != 3 {
+		return time.Time{}, "", false
+	}
+	for _, field := range fields {
+		if len(field) == 0 || len(field) > 4 || !isDigits(field) {
+			return time.Time{}, "", false
+		}
+	}
+
+	// Determine the field order.
+	if len(fields[0]) == 4 {
+		order = dateOrderYMD
+	} else if order == dateOrderYMD && len(fields[2]) == 4 {
+		order = dateOrderDMY
+	}
+	first, _ := strconv.Atoi(fields[0])  // nolint
+	second, _ := strconv.Atoi(fields[1]) // nolint
+	if order == dateOrderMDY && first > 12 && second <= 12 {
+		order = dateOrderDMY
+	} else if order == dateOrderDMY && second > 12 && first <= 12 {
+		order = dateOrderMDY
+	}
+
+	// Build the layout.
+	yearLayout := func(field string) string {
+		if len(field) == 4 {
+			return "2006"
+		}
+		return "06"
+	}
+	var layout string
+	switch order {
+	case dateOrderDMY:
+		layout = "2" + separator + "1" + separator + yearLayout(fields[2])
+	case dateOrderYMD:
+		layout = yearLayout(fields[0]) + separator + "1" + separator + "2"
+	default:
+		layout = "1" + separator + "2" + separator + yearLayout(fields[2])
+	}
+	if timePart == "" {
+		parsed, err := time.Parse(layout, input)
+		return parsed, layout, err == nil
+	}
+	for _, timeLayout := range parseTimeLayouts {
+		if parsed, err := time.Parse(layout+" "+timeLayout, datePart+" "+timePart); err == nil {
+			return parsed, layout + " " + timeLayout, true
+		}
+	}
+
+	return time.Time{}, "", false
+}
+
+// parseUnix parses a Unix epoch timestamp, inferring its unit from the number of digits.
+// Numbers with fewer than nine digits, such as years, are too short to be plausible timestamps, fourteen digit numbers are left for the compact date layout, and numbers with leading zeroes are not timestamps.
+func parseUnix(input string) (time.Time, string, bool) {
+	digits := strings.TrimPrefix(input, "-")
+	whole, fraction, hasFraction := strings.Cut(digits, ".")
+	if !isDigits(whole) || (hasFraction && !isDigits(fraction)) || whole[0] == '0' || len(whole) < minimumUnixDigits {
+		return time.Time{}, "", false
+	}
+	if !strings.HasPrefix(input, "-") && !hasFraction && len(whole) == 14 {
+		return time.Time{}, "", false
+	}
+
+	// Seconds may have a fraction.
+	if hasFraction {
+		seconds, err := strconv.ParseFloat(input, 64)
+		if err != nil || len(whole) > 11 {
+			return time.Time{}, "", false
+		}
+		integer, fractional := math.Modf(seconds)
+		return time.Unix(int64(integer), int64(math.Round(fractional*1e9))).UTC(), LayoutUnix, true
+	}
+
+	value, err := strconv.ParseInt(input, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	switch {
+	case len(whole) <= 11:
+		return time.Unix(value, 0).UTC(), LayoutUnix, true
+	case len(whole) <= 14:
+		return time.UnixMilli(value).UTC(), LayoutUnixMilli, true
+	case len(whole) <= 17:
+		return time.UnixMicro(value).UTC(), LayoutUnixMicro, true
+	default:
+		return time.Unix(0, value).UTC(), LayoutUnixNano, true
+	}
+}
+
+// isDigits reports whether a string is a non-empty run of ASCII digits.
+func isDigits(input string) bool {
+	if input == "" {
+		return false
+	}
+	for i := 0; i < len(input); i++ {
+		if input[i] < '0' || input[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
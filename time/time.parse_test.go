@@ -0,0 +1,77 @@
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseLocale tests ParseLocale().
+func TestParseLocale(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, minute, second, nanosecond int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, nanosecond, time.UTC)
+	}
+	tests := []struct {
+		input    string
+		locale   string
+		expected time.Time
+		layout   string
+	}{
+		// RFC 3339.
+		{"2017-01-08T05:39:40Z", "", utc(2017, time.January, 8, 5, 39, 40, 0), time.RFC3339},
+		{"2017-01-08T05:39:40.123456789Z", "", utc(2017, time.January, 8, 5, 39, 40, 123456789), time.RFC3339},
+		{"2017-01-08T00:39:40.5-05:00", "", utc(2017, time.January, 8, 5, 39, 40, 500000000), time.RFC3339},
+		{"2017-01-08 05:39:40", "", utc(2017, time.January, 8, 5, 39, 40, 0), "2006-01-02 15:04:05"},
+		{"2017-01-08", "", utc(2017, time.January, 8, 0, 0, 0, 0), "2006-01-02"},
+
+		// Mail dates.
+		{"Sun, 08 Jan 2017 00:39:40 -0500", "", utc(2017, time.January, 8, 5, 39, 40, 0), time.RFC1123Z},
+		{"Sun, 8 Jan 2017 00:39:40 -0500 (EST)", "", utc(2017, time.January, 8, 5, 39, 40, 0), "Mon, 2 Jan 2006 15:04:05 -0700"},
+		{"8 Jan 2017 05:39 -0000", "", utc(2017, time.January, 8, 5, 39, 0, 0), "2 Jan 2006 15:04 -0700"},
+		{"08 Jan 17 05:39 +0000", "", utc(2017, time.January, 8, 5, 39, 0, 0), time.RFC822Z},
+
+		// Epoch times.
+		{"1483853980", "", utc(2017, time.January, 8, 5, 39, 40, 0), LayoutUnix},
+		{"1483853980.25", "", utc(2017, time.January, 8, 5, 39, 40, 250000000), LayoutUnix},
+		{"1483853980123", "", utc(2017, time.January, 8, 5, 39, 40, 123000000), LayoutUnixMilli},
+		{"1483853980123456", "", utc(2017, time.January, 8, 5, 39, 40, 123456000), LayoutUnixMicro},
+		{"1483853980123456789", "", utc(2017, time.January, 8, 5, 39, 40, 123456789), LayoutUnixNano},
+		{"20170108", "", utc(2017, time.January, 8, 0, 0, 0, 0), "20060102"},
+
+		// ISO week dates.
+		{"2017-W01-7", "", utc(2017, time.January, 8, 0, 0, 0, 0), LayoutISOWeek},
+		{"2017W017", "", utc(2017, time.January, 8, 0, 0, 0, 0), LayoutISOWeek},
+		{"2020-W01", "", utc(2019, time.December, 30, 0, 0, 0, 0), LayoutISOWeek},
+		{"2020-W53-5", "", utc(2021, time.January, 1, 0, 0, 0, 0), LayoutISOWeek},
+
+		// Written dates.
+		{"January 8, 2017", "", utc(2017, time.January, 8, 0, 0, 0, 0), "January 2, 2006"},
+		{"8 January 2017", "", utc(2017, time.January, 8, 0, 0, 0, 0), "2 January 2006"},
+		{"Jan 8, 2017 5:39 PM", "", utc(2017, time.January, 8, 17, 39, 0, 0), "Jan 2, 2006 3:04 PM"},
+
+		// Numeric dates.
+		{"01/08/2017", "", utc(2017, time.January, 8, 0, 0, 0, 0), "1/2/2006"},
+		{"01/08/2017", "en-US", utc(2017, time.January, 8, 0, 0, 0, 0), "1/2/2006"},
+		{"08/01/2017", "en-GB", utc(2017, time.January, 8, 0, 0, 0, 0), "2/1/2006"},
+		{"8.1.2017 17:39", "de_DE", utc(2017, time.January, 8, 17, 39, 0, 0), "2.1.2006 15:04"},
+		{"8/1/17", "fr", utc(2017, time.January, 8, 0, 0, 0, 0), "2/1/06"},
+		{"17/1/8", "ja-JP", utc(2017, time.January, 8, 0, 0, 0, 0), "06/1/2"},
+		{"2017/01/08 5:39:40 PM", "en-GB", utc(2017, time.January, 8, 17, 39, 40, 0), "2006/1/2 3:04:05 PM"},
+		{"13/01/2017", "en-US", utc(2017, time.January, 13, 0, 0, 0, 0), "2/1/2006"},
+		{"01/13/2017", "en-GB", utc(2017, time.January, 13, 0, 0, 0, 0), "1/2/2006"},
+	}
+	for _, test := range tests {
+		result, layout, err := ParseLocale(test.input, test.locale)
+		if assert.NoError(t, err, test.input) {
+			assert.True(t, test.expected.Equal(result), test.input+": "+result.String())
+			assert.Equal(t, test.layout, layout, test.input)
+		}
+	}
+
+	// Test invalid input.
+	for _, input := range []string{"", "today", "2017-W54-1", "2021-W53", "2017-W01-8", "13/13/2017", "1/2/3/4", "2017-13-45", "012345", "2024", "0", "5", "-1", "12345678.5"} {
+		_, _, err := ParseLocale(input, "")
+		assert.ErrorIs(t, err, ErrUnknownTimeFormat, input)
+	}
+}
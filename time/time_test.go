@@ -1,6 +1,7 @@
 package time
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -36,6 +37,38 @@ func TestParse(t *testing.T) {
 	assert.Error(t, err, "Invalid format.")
 }
 
+// TestSleepContext tests SleepContext().
+func TestSleepContext(t *testing.T) {
+	// Test a complete sleep.
+	start := time.Now()
+	err := SleepContext(context.Background(), 50*time.Millisecond)
+	assert.NoError(t, err, "Complete sleep.")
+	assert.True(t, time.Since(start) >= 50*time.Millisecond, "Woke too soon.")
+
+	// Test cancellation mid-sleep.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	err = SleepContext(ctx, time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Cancelled sleep.")
+	assert.True(t, time.Since(start) < time.Second, "Woke too late.")
+
+	// Test an already cancelled context.
+	err = SleepContext(ctx, 0)
+	assert.Error(t, err, "Cancelled context.")
+}
+
+// TestIncrementalDelay tests IncrementalDelay().
+func TestIncrementalDelay(t *testing.T) {
+	jitterSource := NewJitterSource(1)
+	delay := IncrementalDelay(jitterSource, 0)
+	assert.True(t, delay >= 250*time.Millisecond && delay < 500*time.Millisecond, "First increment.")
+	delay = IncrementalDelay(jitterSource, -999)
+	assert.True(t, delay >= 250*time.Millisecond && delay < 500*time.Millisecond, "Invalid increment.")
+	delay = IncrementalDelay(jitterSource, 999)
+	assert.True(t, delay >= 64*time.Second && delay < 66*time.Second, "Maximum increment.")
+}
+
 // TestSleepIncremental tests SleepIncremental().
 func TestSleepIncremental(t *testing.T) {
 	// Run five iterations.